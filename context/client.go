@@ -0,0 +1,182 @@
+package context
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	jsonrpcclient "github.com/tendermint/tendermint/rpc/jsonrpc/client"
+)
+
+const (
+	DefaultMaxClients          = 64
+	DefaultMaxIdleConnsPerHost = 16
+	DefaultClientIdleTimeout   = 5 * time.Minute
+)
+
+// pooledClient is a client of the pool. Refs counts the waits using its transaction
+// subscriptions, whose websocket would be closed by stopping the client.
+type pooledClient struct {
+	client        rpcclient.Client
+	transport     *http.Transport
+	subscriptions *txSubscriptions
+	refs          int
+	lastUsed      time.Time
+}
+
+// ClientPool keeps one Tendermint RPC client per RPC address so that the
+// underlying HTTP connections are reused across requests. The clients whose
+// subscriptions are in use are not evicted, so the pool may hold more than its
+// maximum number of clients until they are released. Evicting a client does not
+// affect the requests it is making, as only its idle connections are closed.
+type ClientPool struct {
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+
+	maxClients          int
+	maxIdleConnsPerHost int
+	idleTimeout         time.Duration
+}
+
+func NewClientPool(maxClients, maxIdleConnsPerHost int, idleTimeout time.Duration) *ClientPool {
+	return &ClientPool{
+		clients:             make(map[string]*pooledClient),
+		maxClients:          maxClients,
+		maxIdleConnsPerHost: maxIdleConnsPerHost,
+		idleTimeout:         idleTimeout,
+	}
+}
+
+func NewDefaultClientPool() *ClientPool {
+	return NewClientPool(DefaultMaxClients, DefaultMaxIdleConnsPerHost, DefaultClientIdleTimeout)
+}
+
+// Get returns the client for the given RPC address, creating it if it does not exist yet.
+func (p *ClientPool) Get(rpcAddress string) (rpcclient.Client, error) {
+	item, err := p.get(rpcAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return item.client, nil
+}
+
+// acquireSubscriptions returns the transaction subscriptions made over the websocket of
+// the client for the given RPC address, and the function which releases them. The client
+// is not evicted until they are released.
+func (p *ClientPool) acquireSubscriptions(rpcAddress string) (*txSubscriptions, func(), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	item, err := p.getLocked(rpcAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item.refs++
+
+	release := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		item.refs--
+		item.lastUsed = time.Now()
+	}
+
+	return item.subscriptions, release, nil
+}
+
+func (p *ClientPool) get(rpcAddress string) (*pooledClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.getLocked(rpcAddress)
+}
+
+func (p *ClientPool) getLocked(rpcAddress string) (*pooledClient, error) {
+	now := time.Now()
+	p.evict(now)
+
+	if item, ok := p.clients[rpcAddress]; ok {
+		item.lastUsed = now
+		return item, nil
+	}
+
+	httpClient, err := jsonrpcclient.DefaultHTTPClient(rpcAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := httpClient.Transport.(*http.Transport)
+	transport.MaxIdleConns = p.maxIdleConnsPerHost
+	transport.MaxIdleConnsPerHost = p.maxIdleConnsPerHost
+	transport.IdleConnTimeout = p.idleTimeout
+
+	client, err := rpchttp.NewWithClient(rpcAddress, "/websocket", httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(p.clients) >= p.maxClients {
+		if !p.evictOldest() {
+			break
+		}
+	}
+
+	item := &pooledClient{
+		client:    instrumentedClient{Client: client},
+		transport: transport,
+		lastUsed:  now,
+	}
+	item.subscriptions = newTxSubscriptions(item.client, DefaultMaxTxSubscriptions)
+
+	p.clients[rpcAddress] = item
+	return item, nil
+}
+
+// evict removes the clients which are not in use and have not been used for longer than
+// the idle timeout.
+func (p *ClientPool) evict(now time.Time) {
+	for key, item := range p.clients {
+		if item.refs == 0 && now.Sub(item.lastUsed) > p.idleTimeout {
+			p.remove(key)
+		}
+	}
+}
+
+// evictOldest removes the least recently used client which is not in use, and reports
+// whether there was one.
+func (p *ClientPool) evictOldest() bool {
+	var (
+		oldestKey  string
+		oldestTime time.Time
+	)
+
+	for key, item := range p.clients {
+		if item.refs > 0 {
+			continue
+		}
+		if oldestKey == "" || item.lastUsed.Before(oldestTime) {
+			oldestKey, oldestTime = key, item.lastUsed
+		}
+	}
+
+	if oldestKey == "" {
+		return false
+	}
+
+	p.remove(oldestKey)
+	return true
+}
+
+func (p *ClientPool) remove(key string) {
+	item := p.clients[key]
+	if item.client.IsRunning() {
+		_ = item.client.Stop()
+	}
+
+	item.transport.CloseIdleConnections()
+	delete(p.clients, key)
+}
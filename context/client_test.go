@@ -0,0 +1,48 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClientPool_Evict(t *testing.T) {
+	p := NewClientPool(1, 1, time.Minute)
+
+	subscriptions, release, err := p.acquireSubscriptions("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("acquireSubscriptions() error = %v", err)
+	}
+
+	// The client whose subscriptions are in use is kept above the maximum.
+	if _, err := p.Get("http://127.0.0.1:2"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(p.clients) != 2 || p.clients["http://127.0.0.1:1"].subscriptions != subscriptions {
+		t.Fatalf("clients = %d, want 2 with the one in use", len(p.clients))
+	}
+
+	release()
+
+	if _, err := p.Get("http://127.0.0.1:3"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := p.clients["http://127.0.0.1:1"]; ok {
+		t.Fatalf("released client is not evicted")
+	}
+
+	// The idle clients are evicted unless in use.
+	_, release, err = p.acquireSubscriptions("http://127.0.0.1:3")
+	if err != nil {
+		t.Fatalf("acquireSubscriptions() error = %v", err)
+	}
+
+	p.mu.Lock()
+	p.evict(time.Now().Add(time.Hour))
+	p.mu.Unlock()
+
+	if _, ok := p.clients["http://127.0.0.1:3"]; !ok || len(p.clients) != 1 {
+		t.Fatalf("clients = %d, want the one in use", len(p.clients))
+	}
+
+	release()
+}
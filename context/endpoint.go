@@ -0,0 +1,207 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultEndpointProbeInterval = 10 * time.Second
+	DefaultEndpointProbeTimeout  = 5 * time.Second
+)
+
+// Endpoint holds the result of the latest health probe of an RPC address.
+type Endpoint struct {
+	Address     string        `json:"address"`
+	Healthy     bool          `json:"healthy"`
+	CatchingUp  bool          `json:"catching_up"`
+	LatestBlock int64         `json:"latest_block_height"`
+	BlockTime   time.Time     `json:"latest_block_time"`
+	Latency     time.Duration `json:"latency"`
+	Error       string        `json:"error,omitempty"`
+	CheckedAt   time.Time     `json:"checked_at"`
+}
+
+// EndpointSet tracks the health of the configured RPC endpoints and orders
+// them so that queries and broadcasts go to the healthiest one first.
+type EndpointSet struct {
+	mu    sync.RWMutex
+	pool  *ClientPool
+	items []*Endpoint
+}
+
+func NewEndpointSet(pool *ClientPool, addresses []string) *EndpointSet {
+	items := make([]*Endpoint, 0, len(addresses))
+	for _, address := range addresses {
+		items = append(items, &Endpoint{
+			Address: address,
+			Healthy: true,
+		})
+	}
+
+	return &EndpointSet{
+		pool:  pool,
+		items: items,
+	}
+}
+
+// Start probes the endpoints every interval until the given context is done.
+func (s *EndpointSet) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			s.Probe(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Probe queries the status of every endpoint concurrently and records the results.
+func (s *EndpointSet) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, item := range s.Endpoints() {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			s.update(s.probe(ctx, address))
+		}(item.Address)
+	}
+
+	wg.Wait()
+}
+
+func (s *EndpointSet) probe(ctx context.Context, address string) *Endpoint {
+	item := &Endpoint{
+		Address:   address,
+		CheckedAt: time.Now(),
+	}
+
+	client, err := s.pool.Get(address)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultEndpointProbeTimeout)
+	defer cancel()
+
+	res, err := client.Status(ctx)
+	item.Latency = time.Since(item.CheckedAt)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+
+	item.Healthy = !res.SyncInfo.CatchingUp
+	item.CatchingUp = res.SyncInfo.CatchingUp
+	item.LatestBlock = res.SyncInfo.LatestBlockHeight
+	item.BlockTime = res.SyncInfo.LatestBlockTime
+
+	return item
+}
+
+func (s *EndpointSet) update(v *Endpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < len(s.items); i++ {
+		if s.items[i].Address == v.Address {
+			s.items[i] = v
+			return
+		}
+	}
+}
+
+// markFailed flags an endpoint as unhealthy until the next probe.
+func (s *EndpointSet) markFailed(address string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range s.items {
+		if item.Address == address {
+			item.Healthy = false
+			item.Error = err.Error()
+			return
+		}
+	}
+}
+
+// Endpoints returns a snapshot of the endpoints in the configured order.
+func (s *EndpointSet) Endpoints() []Endpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]Endpoint, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, *item)
+	}
+
+	return items
+}
+
+// Sorted returns the endpoint addresses ordered from the healthiest to the least healthy.
+// Healthy endpoints come first, then the ones lagging the fewest blocks behind the
+// highest known block, then the ones with the lowest latency.
+func (s *EndpointSet) Sorted() []string {
+	items := s.Endpoints()
+
+	var maxHeight int64
+	for _, item := range items {
+		if item.LatestBlock > maxHeight {
+			maxHeight = item.LatestBlock
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Healthy != items[j].Healthy {
+			return items[i].Healthy
+		}
+
+		lagI, lagJ := maxHeight-items[i].LatestBlock, maxHeight-items[j].LatestBlock
+		if lagI != lagJ {
+			return lagI < lagJ
+		}
+
+		return items[i].Latency < items[j].Latency
+	})
+
+	addresses := make([]string, 0, len(items))
+	for _, item := range items {
+		addresses = append(addresses, item.Address)
+	}
+
+	return addresses
+}
+
+// isRetryableError reports whether the error was caused by the endpoint itself rather
+// than by the request, in which case the same call can be retried on another endpoint.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if _, ok := status.FromError(err); ok {
+		return false
+	}
+
+	var rpcErr *rpctypes.RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	var abciErr interface{ ABCICode() uint32 }
+	return !errors.As(err, &abciErr)
+}
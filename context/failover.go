@@ -0,0 +1,146 @@
+package context
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tendermint/tendermint/libs/bytes"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+var (
+	ErrNoEndpoints = errors.New("no rpc endpoints configured")
+)
+
+// failoverClient sends each call to the first address and moves on to the next one
+// when the call fails because of the endpoint. Calls which are not overridden here go
+// to the first address only.
+//
+// The calls are bound to the context of the request, including the ones the SDK makes
+// with context.Background(), so that abandoned requests stop querying the endpoints.
+type failoverClient struct {
+	rpcclient.Client
+	ctx       context.Context
+	pool      *ClientPool
+	endpoints *EndpointSet
+	addresses []string
+}
+
+func newFailoverClient(ctx context.Context, pool *ClientPool, endpoints *EndpointSet, addresses []string) (*failoverClient, error) {
+	if len(addresses) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	client, err := pool.Get(addresses[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &failoverClient{
+		Client:    client,
+		ctx:       ctx,
+		pool:      pool,
+		endpoints: endpoints,
+		addresses: addresses,
+	}, nil
+}
+
+func (c *failoverClient) do(fn func(ctx context.Context, client rpcclient.Client) error) (err error) {
+	for _, address := range c.addresses {
+		if err = c.ctx.Err(); err != nil {
+			return err
+		}
+
+		var client rpcclient.Client
+		client, err = c.pool.Get(address)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(c.ctx, client); err == nil || !isRetryableError(err) {
+			return err
+		}
+
+		c.endpoints.markFailed(address, err)
+	}
+
+	return err
+}
+
+func (c *failoverClient) Status(_ context.Context) (result *coretypes.ResultStatus, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.Status(ctx)
+		return err
+	})
+
+	return result, err
+}
+
+func (c *failoverClient) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (result *coretypes.ResultABCIQuery, err error) {
+	return c.ABCIQueryWithOptions(ctx, path, data, rpcclient.DefaultABCIQueryOptions)
+}
+
+func (c *failoverClient) ABCIQueryWithOptions(_ context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (result *coretypes.ResultABCIQuery, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.ABCIQueryWithOptions(ctx, path, data, opts)
+		return err
+	})
+
+	return result, err
+}
+
+func (c *failoverClient) BroadcastTxAsync(_ context.Context, tx tmtypes.Tx) (result *coretypes.ResultBroadcastTx, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.BroadcastTxAsync(ctx, tx)
+		return err
+	})
+
+	return result, err
+}
+
+func (c *failoverClient) BroadcastTxSync(_ context.Context, tx tmtypes.Tx) (result *coretypes.ResultBroadcastTx, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.BroadcastTxSync(ctx, tx)
+		return err
+	})
+
+	return result, err
+}
+
+func (c *failoverClient) BroadcastTxCommit(_ context.Context, tx tmtypes.Tx) (result *coretypes.ResultBroadcastTxCommit, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.BroadcastTxCommit(ctx, tx)
+		return err
+	})
+
+	return result, err
+}
+
+func (c *failoverClient) Tx(_ context.Context, hash []byte, prove bool) (result *coretypes.ResultTx, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.Tx(ctx, hash, prove)
+		return err
+	})
+
+	return result, err
+}
+
+func (c *failoverClient) TxSearch(_ context.Context, query string, prove bool, page, perPage *int, orderBy string) (result *coretypes.ResultTxSearch, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.TxSearch(ctx, query, prove, page, perPage, orderBy)
+		return err
+	})
+
+	return result, err
+}
+
+func (c *failoverClient) UnconfirmedTxs(_ context.Context, limit *int) (result *coretypes.ResultUnconfirmedTxs, err error) {
+	err = c.do(func(ctx context.Context, client rpcclient.Client) (err error) {
+		result, err = client.UnconfirmedTxs(ctx, limit)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,71 @@
+package context
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/bytes"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/solarlabsteam/sentinel-api-backend/metrics"
+)
+
+// instrumentedClient records the latency and the errors of the RPC calls. ABCI queries
+// are labelled with their gRPC method, the other calls with the RPC method name.
+type instrumentedClient struct {
+	rpcclient.Client
+}
+
+func (c instrumentedClient) Status(ctx context.Context) (result *coretypes.ResultStatus, err error) {
+	defer func(start time.Time) { metrics.ObserveRPCCall("status", start, err != nil) }(time.Now())
+	return c.Client.Status(ctx)
+}
+
+func (c instrumentedClient) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (result *coretypes.ResultABCIQuery, err error) {
+	return c.ABCIQueryWithOptions(ctx, path, data, rpcclient.DefaultABCIQueryOptions)
+}
+
+func (c instrumentedClient) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (result *coretypes.ResultABCIQuery, err error) {
+	defer func(start time.Time) {
+		metrics.ObserveRPCCall(path, start, err != nil || !result.Response.IsOK())
+	}(time.Now())
+
+	return c.Client.ABCIQueryWithOptions(ctx, path, data, opts)
+}
+
+func (c instrumentedClient) BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (result *coretypes.ResultBroadcastTx, err error) {
+	defer func(start time.Time) { metrics.ObserveRPCCall("broadcast_tx_async", start, err != nil) }(time.Now())
+	return c.Client.BroadcastTxAsync(ctx, tx)
+}
+
+func (c instrumentedClient) BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (result *coretypes.ResultBroadcastTx, err error) {
+	defer func(start time.Time) { metrics.ObserveRPCCall("broadcast_tx_sync", start, err != nil) }(time.Now())
+	return c.Client.BroadcastTxSync(ctx, tx)
+}
+
+func (c instrumentedClient) BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (result *coretypes.ResultBroadcastTxCommit, err error) {
+	defer func(start time.Time) { metrics.ObserveRPCCall("broadcast_tx_commit", start, err != nil) }(time.Now())
+	return c.Client.BroadcastTxCommit(ctx, tx)
+}
+
+func (c instrumentedClient) Tx(ctx context.Context, hash []byte, prove bool) (result *coretypes.ResultTx, err error) {
+	defer func(start time.Time) {
+		// A transaction which is not included yet is an expected outcome while polling.
+		metrics.ObserveRPCCall("tx", start, err != nil && !strings.Contains(err.Error(), "not found"))
+	}(time.Now())
+
+	return c.Client.Tx(ctx, hash, prove)
+}
+
+func (c instrumentedClient) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (result *coretypes.ResultTxSearch, err error) {
+	defer func(start time.Time) { metrics.ObserveRPCCall("tx_search", start, err != nil) }(time.Now())
+	return c.Client.TxSearch(ctx, query, prove, page, perPage, orderBy)
+}
+
+func (c instrumentedClient) UnconfirmedTxs(ctx context.Context, limit *int) (result *coretypes.ResultUnconfirmedTxs, err error) {
+	defer func(start time.Time) { metrics.ObserveRPCCall("unconfirmed_txs", start, err != nil) }(time.Now())
+	return c.Client.UnconfirmedTxs(ctx, limit)
+}
@@ -1,6 +1,7 @@
 package context
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"strings"
@@ -20,20 +21,127 @@ import (
 	sessiontypes "github.com/sentinel-official/hub/x/session/types"
 	subscriptiontypes "github.com/sentinel-official/hub/x/subscription/types"
 	"github.com/spf13/cobra"
-	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/solarlabsteam/sentinel-api-backend/idempotency"
+	"github.com/solarlabsteam/sentinel-api-backend/jobs"
+	"github.com/solarlabsteam/sentinel-api-backend/keystore"
+	"github.com/solarlabsteam/sentinel-api-backend/metrics"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+	"github.com/solarlabsteam/sentinel-api-backend/webhooks"
 )
 
 type Context struct {
 	client.Context
+	ctx         context.Context
+	config      *types.Config
+	pool        *ClientPool
+	endpoints   *EndpointSet
+	sequences   *SequenceManager
+	jobs        *jobs.Queue
+	webhooks    *webhooks.Dispatcher
+	idempotency *idempotency.Store
+	keystore    *keystore.Keystore
 }
 
 func GetContextFromCmd(cmd *cobra.Command) Context {
-	return Context{Context: client.GetClientContextFromCmd(cmd)}
+	pool := NewDefaultClientPool()
+	return Context{
+		Context:     client.GetClientContextFromCmd(cmd),
+		ctx:         context.Background(),
+		config:      types.DefaultConfig(),
+		pool:        pool,
+		endpoints:   NewEndpointSet(pool, nil),
+		sequences:   NewDefaultSequenceManager(),
+		jobs:        jobs.NewDefaultQueue(),
+		webhooks:    webhooks.NewDefaultDispatcher(),
+		idempotency: idempotency.NewDefaultStore(),
+		keystore:    keystore.NewKeystore(""),
+	}
+}
+
+// WithContext binds the RPC calls and the retry loops to the given context,
+// usually the one of the HTTP request being served.
+func (c Context) WithContext(v context.Context) Context {
+	c.ctx = v
+	return c
+}
+
+func (c Context) WithConfig(v *types.Config) Context {
+	c.config = v
+	return c
+}
+
+func (c Context) WithRPCAddresses(v []string) Context {
+	c.endpoints = NewEndpointSet(c.pool, v)
+	return c
+}
+
+func (c Context) WithJobs(v *jobs.Queue) Context {
+	c.jobs = v
+	return c
+}
+
+func (c Context) WithWebhooks(v *webhooks.Dispatcher) Context {
+	c.webhooks = v
+	return c
+}
+
+func (c Context) WithIdempotency(v *idempotency.Store) Context {
+	c.idempotency = v
+	return c
+}
+
+func (c Context) WithKeystore(v *keystore.Keystore) Context {
+	c.keystore = v
+	return c
+}
+
+func (c Context) Config() *types.Config {
+	return c.config
+}
+
+func (c Context) Endpoints() *EndpointSet {
+	return c.endpoints
+}
+
+func (c Context) Jobs() *jobs.Queue {
+	return c.jobs
+}
+
+func (c Context) Webhooks() *webhooks.Dispatcher {
+	return c.webhooks
+}
+
+func (c Context) Idempotency() *idempotency.Store {
+	return c.idempotency
+}
+
+func (c Context) Keystore() *keystore.Keystore {
+	return c.keystore
+}
+
+// RequestContext returns the context the RPC calls are bound to.
+func (c Context) RequestContext() context.Context {
+	return c.ctx
+}
+
+// getClient returns a client bound to the context of the request, which uses the given
+// RPC address or fails over between the configured endpoints when the address is empty.
+func (c Context) getClient(rpcAddress string) (rpcclient.Client, error) {
+	addresses := []string{rpcAddress}
+	if rpcAddress == "" {
+		addresses = c.endpoints.Sorted()
+	}
+
+	return newFailoverClient(c.ctx, c.pool, c.endpoints, addresses)
 }
 
 func (c Context) QueryTx(rpcAddress string, hash string) (result *coretypes.ResultTx, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +151,7 @@ func (c Context) QueryTx(rpcAddress string, hash string) (result *coretypes.Resu
 		return nil, err
 	}
 
-	result, err = c.Client.Tx(context.Background(), buf, false)
+	result, err = c.Client.Tx(c.ctx, buf, false)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return nil, nil
@@ -55,8 +163,55 @@ func (c Context) QueryTx(rpcAddress string, hash string) (result *coretypes.Resu
 	return result, nil
 }
 
+func (c Context) QueryTxSearch(rpcAddress string, query string, page, perPage int, orderBy string) (result *coretypes.ResultTxSearch, err error) {
+	c.Client, err = c.getClient(rpcAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = c.Client.TxSearch(c.ctx, query, false, &page, &perPage, orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// QueryUnconfirmedTx reports whether the transaction is in the mempool. Only the first
+// page of the mempool is checked, which the RPC caps at 100 transactions.
+func (c Context) QueryUnconfirmedTx(rpcAddress string, hash string) (found bool, err error) {
+	c.Client, err = c.getClient(rpcAddress)
+	if err != nil {
+		return false, err
+	}
+
+	buf, err := hex.DecodeString(hash)
+	if err != nil {
+		return false, err
+	}
+
+	limit := 100
+	result, err := c.Client.UnconfirmedTxs(c.ctx, &limit)
+	if err != nil {
+		return false, err
+	}
+
+	for _, tx := range result.Txs {
+		if bytes.Equal(tx.Hash(), buf) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (c Context) QueryTxWithRetry(rpcAddress string, hash string, tries int64) (result *coretypes.ResultTx, err error) {
+	var polls int64
+	defer func() { metrics.TxQueryPolls.Observe(float64(polls)) }()
+
 	for ; tries > 0; tries-- {
+		polls++
+
 		result, err = c.QueryTx(rpcAddress, hash)
 		if err != nil {
 			return nil, err
@@ -65,27 +220,35 @@ func (c Context) QueryTxWithRetry(rpcAddress string, hash string, tries int64) (
 			break
 		}
 
-		time.Sleep(1 * time.Second)
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-time.After(DefaultTxPollInterval):
+		}
 	}
 
 	return result, nil
 }
 
 func (c Context) QueryAccount(rpcAddress string, accAddr sdk.AccAddress) (result authtypes.AccountI, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qc := authtypes.NewQueryClient(c)
 	resp, err := qc.Account(
-		context.Background(),
+		c.ctx,
 		&authtypes.QueryAccountRequest{
 			Address: accAddr.String(),
 		},
 	)
 
 	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, types.NewError(types.ErrorCodeAccountNotFound, err)
+		}
+
 		return nil, err
 	}
 	if err = c.InterfaceRegistry.UnpackAny(resp.Account, &result); err != nil {
@@ -96,14 +259,14 @@ func (c Context) QueryAccount(rpcAddress string, accAddr sdk.AccAddress) (result
 }
 
 func (c Context) QueryBalances(rpcAddress string, accAddr sdk.AccAddress, pagination *query.PageRequest) (result sdk.Coins, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qc := banktypes.NewQueryClient(c)
 	resp, err := qc.AllBalances(
-		context.Background(),
+		c.ctx,
 		&banktypes.QueryAllBalancesRequest{
 			Address:    accAddr.String(),
 			Pagination: pagination,
@@ -118,14 +281,14 @@ func (c Context) QueryBalances(rpcAddress string, accAddr sdk.AccAddress, pagina
 }
 
 func (c Context) QueryFeegrantAllowancesByGranter(rpcAddress string, accAddr sdk.AccAddress, pagination *query.PageRequest) (result []*feegrant.Grant, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qc := feegrant.NewQueryClient(c)
 	resp, err := qc.AllowancesByGranter(
-		context.Background(),
+		c.ctx,
 		&feegrant.QueryAllowancesByGranterRequest{
 			Granter:    accAddr.String(),
 			Pagination: pagination,
@@ -140,14 +303,14 @@ func (c Context) QueryFeegrantAllowancesByGranter(rpcAddress string, accAddr sdk
 }
 
 func (c Context) QueryFeegrantAllowances(rpcAddress string, accAddr sdk.AccAddress, pagination *query.PageRequest) (result []*feegrant.Grant, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qc := feegrant.NewQueryClient(c)
 	resp, err := qc.Allowances(
-		context.Background(),
+		c.ctx,
 		&feegrant.QueryAllowancesRequest{
 			Grantee:    accAddr.String(),
 			Pagination: pagination,
@@ -162,14 +325,14 @@ func (c Context) QueryFeegrantAllowances(rpcAddress string, accAddr sdk.AccAddre
 }
 
 func (c Context) QueryDeposit(rpcAddress string, accAddr sdk.AccAddress) (result *deposittypes.Deposit, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := deposittypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryDeposit(
-		context.Background(),
+		c.ctx,
 		deposittypes.NewQueryDepositRequest(
 			accAddr,
 		),
@@ -183,14 +346,14 @@ func (c Context) QueryDeposit(rpcAddress string, accAddr sdk.AccAddress) (result
 }
 
 func (c Context) QueryDeposits(rpcAddress string, pagination *query.PageRequest) (result deposittypes.Deposits, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := deposittypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryDeposits(
-		context.Background(),
+		c.ctx,
 		deposittypes.NewQueryDepositsRequest(
 			pagination,
 		),
@@ -204,14 +367,14 @@ func (c Context) QueryDeposits(rpcAddress string, pagination *query.PageRequest)
 }
 
 func (c Context) QueryNode(rpcAddress string, nodeAddr hubtypes.NodeAddress) (result *nodetypes.Node, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := nodetypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryNode(
-		context.Background(),
+		c.ctx,
 		nodetypes.NewQueryNodeRequest(
 			nodeAddr,
 		),
@@ -225,14 +388,14 @@ func (c Context) QueryNode(rpcAddress string, nodeAddr hubtypes.NodeAddress) (re
 }
 
 func (c Context) QueryNodes(rpcAddress string, status hubtypes.Status, pagination *query.PageRequest) (result nodetypes.Nodes, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := nodetypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryNodes(
-		context.Background(),
+		c.ctx,
 		nodetypes.NewQueryNodesRequest(
 			status,
 			pagination,
@@ -247,14 +410,14 @@ func (c Context) QueryNodes(rpcAddress string, status hubtypes.Status, paginatio
 }
 
 func (c Context) QueryNodesForPlan(rpcAddress string, id uint64, status hubtypes.Status, pagination *query.PageRequest) (result nodetypes.Nodes, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := nodetypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryNodesForPlan(
-		context.Background(),
+		c.ctx,
 		nodetypes.NewQueryNodesForPlanRequest(
 			id,
 			status,
@@ -270,14 +433,14 @@ func (c Context) QueryNodesForPlan(rpcAddress string, id uint64, status hubtypes
 }
 
 func (c Context) QueryPlan(rpcAddress string, id uint64) (result *plantypes.Plan, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := plantypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryPlan(
-		context.Background(),
+		c.ctx,
 		plantypes.NewQueryPlanRequest(
 			id,
 		),
@@ -291,14 +454,14 @@ func (c Context) QueryPlan(rpcAddress string, id uint64) (result *plantypes.Plan
 }
 
 func (c Context) QueryPlans(rpcAddress string, status hubtypes.Status, pagination *query.PageRequest) (result plantypes.Plans, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := plantypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryPlans(
-		context.Background(),
+		c.ctx,
 		plantypes.NewQueryPlansRequest(
 			status,
 			pagination,
@@ -313,14 +476,14 @@ func (c Context) QueryPlans(rpcAddress string, status hubtypes.Status, paginatio
 }
 
 func (c Context) QueryPlansForProvider(rpcAddress string, provAddr hubtypes.ProvAddress, status hubtypes.Status, pagination *query.PageRequest) (result plantypes.Plans, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := plantypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryPlansForProvider(
-		context.Background(),
+		c.ctx,
 		plantypes.NewQueryPlansForProviderRequest(
 			provAddr,
 			status,
@@ -336,14 +499,14 @@ func (c Context) QueryPlansForProvider(rpcAddress string, provAddr hubtypes.Prov
 }
 
 func (c Context) QueryProvider(rpcAddress string, provAddr hubtypes.ProvAddress) (result *providertypes.Provider, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := providertypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryProvider(
-		context.Background(),
+		c.ctx,
 		providertypes.NewQueryProviderRequest(
 			provAddr,
 		),
@@ -357,14 +520,14 @@ func (c Context) QueryProvider(rpcAddress string, provAddr hubtypes.ProvAddress)
 }
 
 func (c Context) QueryProviders(rpcAddress string, status hubtypes.Status, pagination *query.PageRequest) (result providertypes.Providers, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := providertypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryProviders(
-		context.Background(),
+		c.ctx,
 		providertypes.NewQueryProvidersRequest(
 			status,
 			pagination,
@@ -379,14 +542,14 @@ func (c Context) QueryProviders(rpcAddress string, status hubtypes.Status, pagin
 }
 
 func (c Context) QuerySession(rpcAddress string, id uint64) (result *sessiontypes.Session, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := sessiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QuerySession(
-		context.Background(),
+		c.ctx,
 		sessiontypes.NewQuerySessionRequest(
 			id,
 		),
@@ -400,14 +563,14 @@ func (c Context) QuerySession(rpcAddress string, id uint64) (result *sessiontype
 }
 
 func (c Context) QuerySessions(rpcAddress string, pagination *query.PageRequest) (result sessiontypes.Sessions, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := sessiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QuerySessions(
-		context.Background(),
+		c.ctx,
 		sessiontypes.NewQuerySessionsRequest(
 			pagination,
 		),
@@ -421,14 +584,14 @@ func (c Context) QuerySessions(rpcAddress string, pagination *query.PageRequest)
 }
 
 func (c Context) QuerySessionsForAccount(rpcAddress string, accAddr sdk.AccAddress, pagination *query.PageRequest) (result sessiontypes.Sessions, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := sessiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QuerySessionsForAccount(
-		context.Background(),
+		c.ctx,
 		sessiontypes.NewQuerySessionsForAccountRequest(
 			accAddr,
 			pagination,
@@ -443,14 +606,14 @@ func (c Context) QuerySessionsForAccount(rpcAddress string, accAddr sdk.AccAddre
 }
 
 func (c Context) QuerySubscription(rpcAddress string, id uint64) (result subscriptiontypes.Subscription, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := subscriptiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QuerySubscription(
-		context.Background(),
+		c.ctx,
 		subscriptiontypes.NewQuerySubscriptionRequest(
 			id,
 		),
@@ -467,14 +630,14 @@ func (c Context) QuerySubscription(rpcAddress string, id uint64) (result subscri
 }
 
 func (c Context) QuerySubscriptions(rpcAddress string, pagination *query.PageRequest) (result subscriptiontypes.Subscriptions, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := subscriptiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QuerySubscriptions(
-		context.Background(),
+		c.ctx,
 		subscriptiontypes.NewQuerySubscriptionsRequest(
 			pagination,
 		),
@@ -497,14 +660,14 @@ func (c Context) QuerySubscriptions(rpcAddress string, pagination *query.PageReq
 }
 
 func (c Context) QuerySubscriptionsForAccount(rpcAddress string, accAddr sdk.AccAddress, pagination *query.PageRequest) (result subscriptiontypes.Subscriptions, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := subscriptiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QuerySubscriptionsForAccount(
-		context.Background(),
+		c.ctx,
 		subscriptiontypes.NewQuerySubscriptionsForAccountRequest(
 			accAddr,
 			pagination,
@@ -528,14 +691,14 @@ func (c Context) QuerySubscriptionsForAccount(rpcAddress string, accAddr sdk.Acc
 }
 
 func (c Context) QueryAllocation(rpcAddress string, id uint64, accAddr sdk.AccAddress) (result *subscriptiontypes.Allocation, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := subscriptiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryAllocation(
-		context.Background(),
+		c.ctx,
 		subscriptiontypes.NewQueryAllocationRequest(
 			id,
 			accAddr,
@@ -550,14 +713,14 @@ func (c Context) QueryAllocation(rpcAddress string, id uint64, accAddr sdk.AccAd
 }
 
 func (c Context) QueryAllocations(rpcAddress string, id uint64, pagination *query.PageRequest) (result subscriptiontypes.Allocations, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := subscriptiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QueryAllocations(
-		context.Background(),
+		c.ctx,
 		subscriptiontypes.NewQueryAllocationsRequest(
 			id,
 			pagination,
@@ -571,15 +734,58 @@ func (c Context) QueryAllocations(rpcAddress string, id uint64, pagination *quer
 	return resp.Allocations, nil
 }
 
+// QueryActiveSessions returns the active sessions of the account, going through all
+// the pages of its sessions.
+func (c Context) QueryActiveSessions(rpcAddress string, accAddr sdk.AccAddress) (result sessiontypes.Sessions, err error) {
+	c.Client, err = c.getClient(rpcAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		qsc        = sessiontypes.NewQueryServiceClient(c)
+		pagination = &query.PageRequest{Limit: query.DefaultLimit}
+	)
+
+	for {
+		resp, err := qsc.QuerySessionsForAccount(
+			c.ctx,
+			sessiontypes.NewQuerySessionsForAccountRequest(
+				accAddr,
+				pagination,
+			),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Sessions {
+			if item.Status.Equal(hubtypes.StatusActive) {
+				result = append(result, item)
+			}
+		}
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			return result, nil
+		}
+
+		pagination = &query.PageRequest{
+			Key:   resp.Pagination.NextKey,
+			Limit: query.DefaultLimit,
+		}
+	}
+}
+
 func (c Context) QueryActiveSession(rpcAddress string, accAddr sdk.AccAddress) (result *sessiontypes.Session, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	qsc := sessiontypes.NewQueryServiceClient(c)
 	resp, err := qsc.QuerySessionsForAccount(
-		context.Background(),
+		c.ctx,
 		sessiontypes.NewQuerySessionsForAccountRequest(
 			accAddr,
 			&query.PageRequest{
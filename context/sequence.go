@@ -0,0 +1,194 @@
+package context
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	DefaultSequenceIdleTimeout = 10 * time.Minute
+)
+
+var (
+	sequenceMismatchRegexp = regexp.MustCompile(`account sequence mismatch, expected (\d+)`)
+)
+
+// accountSequence is the local state of an account. The lock is held for the whole
+// transaction and is a channel, so that waiting for it can be abandoned with the request.
+// The other fields are guarded by the mutex of the manager.
+type accountSequence struct {
+	lock     chan struct{}
+	refs     int
+	lastUsed time.Time
+
+	synced        bool
+	accountNumber uint64
+	sequence      uint64
+}
+
+// SequenceManager hands out the sequences of the accounts locally and serializes the
+// transactions of each account up to their broadcast, so that concurrent transactions
+// of an account do not use the same sequence. The chain is queried for the sequence of
+// an account when it is first used, and again after a sequence mismatch.
+type SequenceManager struct {
+	mu       sync.Mutex
+	accounts map[string]*accountSequence
+
+	idleTimeout time.Duration
+}
+
+func sequenceKey(chainID string, accAddr sdk.AccAddress) string {
+	return chainID + "/" + accAddr.String()
+}
+
+func NewSequenceManager(idleTimeout time.Duration) *SequenceManager {
+	return &SequenceManager{
+		accounts:    make(map[string]*accountSequence),
+		idleTimeout: idleTimeout,
+	}
+}
+
+func NewDefaultSequenceManager() *SequenceManager {
+	return NewSequenceManager(DefaultSequenceIdleTimeout)
+}
+
+// acquire locks the account of the given chain, waiting for the transaction of the
+// account in progress if any.
+func (m *SequenceManager) acquire(ctx context.Context, chainID string, accAddr sdk.AccAddress) (*accountSequence, error) {
+	key := sequenceKey(chainID, accAddr)
+
+	m.mu.Lock()
+	now := time.Now()
+	m.evict(now)
+
+	item, ok := m.accounts[key]
+	if !ok {
+		item = &accountSequence{
+			lock: make(chan struct{}, 1),
+		}
+		m.accounts[key] = item
+	}
+
+	item.refs++
+	item.lastUsed = now
+	m.mu.Unlock()
+
+	select {
+	case item.lock <- struct{}{}:
+		return item, nil
+	case <-ctx.Done():
+		// The lock is held by another transaction, so only the reference is dropped.
+		m.mu.Lock()
+		item.refs--
+		m.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// release unlocks the account. It must only be called once for each acquire.
+func (m *SequenceManager) release(item *accountSequence) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item.refs--
+	item.lastUsed = time.Now()
+
+	select {
+	case <-item.lock:
+	default:
+	}
+}
+
+// evict removes the accounts which are not in use and have not been used for longer
+// than the idle timeout, so that their sequences are queried again.
+func (m *SequenceManager) evict(now time.Time) {
+	for key, item := range m.accounts {
+		if item.refs == 0 && now.Sub(item.lastUsed) > m.idleTimeout {
+			delete(m.accounts, key)
+		}
+	}
+}
+
+// peek returns the local sequence of the account, if it is known.
+func (m *SequenceManager) peek(chainID string, accAddr sdk.AccAddress) (accountNumber, sequence uint64, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.accounts[sequenceKey(chainID, accAddr)]
+	if !ok || !item.synced {
+		return 0, 0, false
+	}
+
+	return item.accountNumber, item.sequence, true
+}
+
+func (m *SequenceManager) get(item *accountSequence) (accountNumber, sequence uint64, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return item.accountNumber, item.sequence, item.synced
+}
+
+func (m *SequenceManager) set(item *accountSequence, accountNumber, sequence uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item.synced = true
+	item.accountNumber = accountNumber
+	item.sequence = sequence
+}
+
+// invalidate marks the account to be queried again, for instance when the outcome of
+// a broadcast is unknown.
+func (m *SequenceManager) invalidate(item *accountSequence) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item.synced = false
+}
+
+// update advances the sequence of the account after a broadcast, or resyncs it when
+// the chain reported a sequence mismatch. The transactions which failed in CheckTx did
+// not use their sequence.
+func (m *SequenceManager) update(item *accountSequence, result *sdk.TxResponse) {
+	if result.Code == sdkerrors.SuccessABCICode || result.Height > 0 {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		item.sequence++
+		return
+	}
+
+	if isWrongSequence(result.Codespace, result.Code) {
+		m.resync(item, result.RawLog)
+	}
+}
+
+// resync takes the sequence expected by the chain from the error log of a sequence
+// mismatch, or marks the account to be queried again when the log does not carry it.
+// Logs of other errors are ignored.
+func (m *SequenceManager) resync(item *accountSequence, log string) {
+	if !strings.Contains(log, sdkerrors.ErrWrongSequence.Error()) {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v := sequenceMismatchRegexp.FindStringSubmatch(log); v != nil {
+		if sequence, err := strconv.ParseUint(v[1], 10, 64); err == nil {
+			item.sequence = sequence
+			return
+		}
+	}
+
+	item.synced = false
+}
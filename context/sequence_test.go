@@ -0,0 +1,169 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	testAccAddr = sdk.AccAddress("test_address________")
+)
+
+func TestSequenceManager_Update(t *testing.T) {
+	m := NewDefaultSequenceManager()
+
+	item, err := m.acquire(context.Background(), "chain", testAccAddr)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer m.release(item)
+
+	if _, _, ok := m.get(item); ok {
+		t.Fatalf("get() of a new account = true")
+	}
+
+	m.set(item, 1, 5)
+	if accountNumber, sequence, ok := m.peek("chain", testAccAddr); !ok || accountNumber != 1 || sequence != 5 {
+		t.Fatalf("peek() = %d, %d, %t, want 1, 5, true", accountNumber, sequence, ok)
+	}
+	if _, _, ok := m.peek("other", testAccAddr); ok {
+		t.Fatalf("peek() of another chain = true")
+	}
+
+	// Accepted in CheckTx, or included in a block even if it failed.
+	m.update(item, &sdk.TxResponse{Code: sdkerrors.SuccessABCICode})
+	if _, sequence, _ := m.get(item); sequence != 6 {
+		t.Fatalf("get() = %d, want 6", sequence)
+	}
+
+	m.update(item, &sdk.TxResponse{Code: sdkerrors.ErrInsufficientFunds.ABCICode(), Height: 10})
+	if _, sequence, _ := m.get(item); sequence != 7 {
+		t.Fatalf("get() = %d, want 7", sequence)
+	}
+
+	// Rejected in CheckTx, which does not use the sequence.
+	m.update(item, &sdk.TxResponse{Codespace: sdkerrors.RootCodespace, Code: sdkerrors.ErrInsufficientFunds.ABCICode()})
+	if _, sequence, _ := m.get(item); sequence != 7 {
+		t.Fatalf("get() = %d, want 7", sequence)
+	}
+}
+
+func TestSequenceManager_Resync(t *testing.T) {
+	m := NewDefaultSequenceManager()
+
+	item, err := m.acquire(context.Background(), "chain", testAccAddr)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer m.release(item)
+
+	m.set(item, 1, 5)
+	m.update(
+		item,
+		&sdk.TxResponse{
+			Codespace: sdkerrors.ErrWrongSequence.Codespace(),
+			Code:      sdkerrors.ErrWrongSequence.ABCICode(),
+			RawLog:    "account sequence mismatch, expected 9, got 5: incorrect account sequence",
+		},
+	)
+	if _, sequence, ok := m.get(item); !ok || sequence != 9 {
+		t.Fatalf("get() = %d, %t, want 9, true", sequence, ok)
+	}
+
+	// Logs of other errors are ignored.
+	m.resync(item, "insufficient fees")
+	if _, sequence, ok := m.get(item); !ok || sequence != 9 {
+		t.Fatalf("get() = %d, %t, want 9, true", sequence, ok)
+	}
+
+	// A mismatch without the expected sequence requires a query.
+	m.resync(item, "incorrect account sequence")
+	if _, _, ok := m.get(item); ok {
+		t.Fatalf("get() after a resync without a sequence = true")
+	}
+
+	m.set(item, 1, 9)
+	m.invalidate(item)
+	if _, _, ok := m.peek("chain", testAccAddr); ok {
+		t.Fatalf("peek() of an invalidated account = true")
+	}
+}
+
+func TestSequenceManager_Acquire(t *testing.T) {
+	m := NewDefaultSequenceManager()
+
+	item, err := m.acquire(context.Background(), "chain", testAccAddr)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	// The account is locked until it is released.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := m.acquire(ctx, "chain", testAccAddr); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan *accountSequence)
+	go func() {
+		v, _ := m.acquire(context.Background(), "chain", testAccAddr)
+		done <- v
+	}()
+
+	// The abandoned acquire does not unlock the account of the first transaction.
+	select {
+	case <-done:
+		t.Fatalf("acquire() of a locked account returned")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	m.release(item)
+
+	select {
+	case v := <-done:
+		if v != item {
+			t.Fatalf("acquire() returned another account")
+		}
+		m.release(v)
+	case <-time.After(time.Second):
+		t.Fatalf("acquire() is not released")
+	}
+}
+
+func TestSequenceManager_Evict(t *testing.T) {
+	m := NewSequenceManager(time.Millisecond)
+
+	item, err := m.acquire(context.Background(), "chain", testAccAddr)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	m.set(item, 1, 5)
+	time.Sleep(2 * time.Millisecond)
+
+	// Accounts in use are kept.
+	m.mu.Lock()
+	m.evict(time.Now())
+	m.mu.Unlock()
+
+	if _, _, ok := m.peek("chain", testAccAddr); !ok {
+		t.Fatalf("peek() of an account in use = false")
+	}
+
+	m.release(item)
+	time.Sleep(2 * time.Millisecond)
+
+	m.mu.Lock()
+	m.evict(time.Now())
+	m.mu.Unlock()
+
+	if _, _, ok := m.peek("chain", testAccAddr); ok {
+		t.Fatalf("peek() of an evicted account = true")
+	}
+}
@@ -0,0 +1,272 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/service"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/solarlabsteam/sentinel-api-backend/metrics"
+)
+
+const (
+	// DefaultTxPollInterval is the interval of the queries of a transaction which is
+	// waited for without a subscription, either because there is none or because it
+	// ended without the event of the transaction.
+	DefaultTxPollInterval = time.Second
+
+	// DefaultTxFallbackPollInterval is the interval of the queries made while waiting for
+	// the event of a transaction. The node does not report the subscriptions it refused,
+	// for instance above its limit of subscriptions per client, so the event may never come.
+	DefaultTxFallbackPollInterval = 5 * time.Second
+
+	// DefaultMaxTxSubscriptions is the number of transactions subscribed to at once over
+	// the websocket of a client, which is the default limit of subscriptions per client
+	// of Tendermint. The waits above it query the transaction every DefaultTxPollInterval
+	// instead.
+	DefaultMaxTxSubscriptions = 5
+
+	txSubscriber = "sentinelapi"
+)
+
+var (
+	ErrTooManySubscriptions = errors.New("too many transaction subscriptions")
+
+	txSubscriberSeq uint64
+)
+
+// txSubscription is the subscription to the event of a transaction, which is shared by
+// the waits for the transaction. Done is closed once the event is received, or once the
+// subscription ends without it, in which case event is nil.
+type txSubscription struct {
+	subscriber string
+	query      string
+	refs       int
+	done       chan struct{}
+	stop       chan struct{}
+	event      *coretypes.ResultEvent
+}
+
+// txSubscriptions are the transaction subscriptions made over the websocket of a client.
+// The client of Tendermint keeps one subscription per query, so the waits for the same
+// transaction share its subscription.
+type txSubscriptions struct {
+	mu     sync.Mutex
+	client rpcclient.Client
+	items  map[string]*txSubscription
+	limit  int
+}
+
+func newTxSubscriptions(client rpcclient.Client, limit int) *txSubscriptions {
+	return &txSubscriptions{
+		client: client,
+		items:  make(map[string]*txSubscription),
+		limit:  limit,
+	}
+}
+
+// subscribe subscribes to the event of the transaction with the given hash, or joins the
+// subscription to it which is in progress. The websocket of the client is started on
+// first use. The subscription must be released once done with.
+func (s *txSubscriptions) subscribe(ctx context.Context, hash string) (*txSubscription, error) {
+	s.mu.Lock()
+	if item, ok := s.items[hash]; ok {
+		item.refs++
+		s.mu.Unlock()
+
+		return item, nil
+	}
+	if len(s.items) >= s.limit {
+		s.mu.Unlock()
+		return nil, ErrTooManySubscriptions
+	}
+
+	item := &txSubscription{
+		subscriber: fmt.Sprintf("%s-%d", txSubscriber, atomic.AddUint64(&txSubscriberSeq, 1)),
+		query:      fmt.Sprintf("%s='%s' AND %s='%s'", tmtypes.EventTypeKey, tmtypes.EventTx, tmtypes.TxHashKey, hash),
+		refs:       1,
+		done:       make(chan struct{}),
+		stop:       make(chan struct{}),
+	}
+
+	s.items[hash] = item
+	s.mu.Unlock()
+
+	events, err := s.start(ctx, item)
+	if err != nil {
+		// The waits which joined in the meantime poll the transaction.
+		s.mu.Lock()
+		delete(s.items, hash)
+		s.mu.Unlock()
+
+		close(item.done)
+		return nil, err
+	}
+
+	go func() {
+		defer close(item.done)
+
+		select {
+		case event, ok := <-events:
+			if ok {
+				item.event = &event
+			}
+		case <-item.stop:
+		}
+	}()
+
+	return item, nil
+}
+
+func (s *txSubscriptions) start(ctx context.Context, item *txSubscription) (<-chan coretypes.ResultEvent, error) {
+	if !s.client.IsRunning() {
+		if err := s.client.Start(); err != nil && !errors.Is(err, service.ErrAlreadyStarted) {
+			return nil, err
+		}
+	}
+
+	return s.client.Subscribe(ctx, item.subscriber, item.query)
+}
+
+// release leaves the subscription, which is ended once all of its waits left it.
+func (s *txSubscriptions) release(hash string, item *txSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item.refs--
+	if item.refs > 0 || s.items[hash] != item {
+		return
+	}
+
+	delete(s.items, hash)
+	close(item.stop)
+
+	go func() {
+		_ = s.client.Unsubscribe(context.Background(), item.subscriber, item.query)
+	}()
+}
+
+// subscribeTx subscribes to the event of the transaction with the given hash over the
+// websocket of the first client of the failover order, and returns the function which
+// releases the subscription and the client.
+func (c Context) subscribeTx(rpcAddress, hash string) (*txSubscription, func(), error) {
+	if rpcAddress == "" {
+		addresses := c.endpoints.Sorted()
+		if len(addresses) == 0 {
+			return nil, nil, ErrNoEndpoints
+		}
+
+		rpcAddress = addresses[0]
+	}
+
+	subscriptions, releaseClient, err := c.pool.acquireSubscriptions(rpcAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item, err := subscriptions.subscribe(c.ctx, hash)
+	if err != nil {
+		releaseClient()
+		return nil, nil, err
+	}
+
+	release := func() {
+		subscriptions.release(hash, item)
+		releaseClient()
+	}
+
+	return item, release, nil
+}
+
+// WaitForTx waits up to tries seconds for the transaction with the given hash to be
+// included in a block, and returns nil if it is not. The inclusion is reported by a
+// websocket subscription, and the transaction is only queried when subscribing, every
+// DefaultTxFallbackPollInterval and at the deadline. Once the subscription ends without
+// the event, or when the websocket is not available or has no room for another
+// subscription, the transaction is queried every DefaultTxPollInterval instead.
+func (c Context) WaitForTx(rpcAddress string, hash string, tries int64) (result *coretypes.ResultTx, err error) {
+	hash = strings.ToUpper(hash)
+
+	subscription, release, err := c.subscribeTx(rpcAddress, hash)
+	if err != nil {
+		result, err = c.QueryTxWithRetry(rpcAddress, hash, tries)
+		if result != nil {
+			metrics.TxConfirmationsTotal.WithLabelValues("polling").Inc()
+		}
+
+		return result, err
+	}
+
+	defer release()
+
+	deadline := time.NewTimer(time.Duration(tries) * time.Second)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(DefaultTxFallbackPollInterval)
+	defer ticker.Stop()
+
+	// The transaction may be included before the subscription is made.
+	var (
+		done = subscription.done
+		poll = true
+	)
+
+	for {
+		if poll {
+			result, err = c.QueryTx(rpcAddress, hash)
+			if err != nil {
+				return nil, err
+			}
+			if result != nil {
+				metrics.TxConfirmationsTotal.WithLabelValues("query").Inc()
+				return result, nil
+			}
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-done:
+			done = nil
+
+			var (
+				data tmtypes.EventDataTx
+				ok   bool
+			)
+			if subscription.event != nil {
+				data, ok = subscription.event.Data.(tmtypes.EventDataTx)
+			}
+			if !ok {
+				ticker.Reset(DefaultTxPollInterval)
+				poll = true
+				continue
+			}
+
+			metrics.TxConfirmationsTotal.WithLabelValues("websocket").Inc()
+			return &coretypes.ResultTx{
+				Hash:     tmtypes.Tx(data.Tx).Hash(),
+				Height:   data.Height,
+				Index:    data.Index,
+				TxResult: data.Result,
+				Tx:       data.Tx,
+			}, nil
+		case <-ticker.C:
+			poll = true
+		case <-deadline.C:
+			result, err = c.QueryTx(rpcAddress, hash)
+			if result != nil {
+				metrics.TxConfirmationsTotal.WithLabelValues("query").Inc()
+			}
+
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// fakeEventsClient keeps a channel per subscribed query, as the HTTP client does.
+type fakeEventsClient struct {
+	rpcclient.Client
+
+	mu           sync.Mutex
+	subscribers  map[string]string
+	events       map[string]chan coretypes.ResultEvent
+	unsubscribed chan string
+}
+
+func newFakeEventsClient() *fakeEventsClient {
+	return &fakeEventsClient{
+		subscribers:  make(map[string]string),
+		events:       make(map[string]chan coretypes.ResultEvent),
+		unsubscribed: make(chan string, 8),
+	}
+}
+
+func (c *fakeEventsClient) IsRunning() bool {
+	return true
+}
+
+func (c *fakeEventsClient) Subscribe(_ context.Context, subscriber, query string, _ ...int) (<-chan coretypes.ResultEvent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.events[query]; ok {
+		return nil, errors.New("already subscribed")
+	}
+
+	c.subscribers[query] = subscriber
+	c.events[query] = make(chan coretypes.ResultEvent, 1)
+	return c.events[query], nil
+}
+
+func (c *fakeEventsClient) Unsubscribe(_ context.Context, subscriber, query string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscribers[query] != subscriber {
+		return errors.New("subscription not found")
+	}
+
+	delete(c.subscribers, query)
+	delete(c.events, query)
+	c.unsubscribed <- query
+	return nil
+}
+
+func (c *fakeEventsClient) publish(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.events[query] <- coretypes.ResultEvent{Query: query}
+}
+
+func waitDone(t *testing.T, item *txSubscription) {
+	t.Helper()
+
+	select {
+	case <-item.done:
+	case <-time.After(time.Second):
+		t.Fatalf("subscription is not done")
+	}
+}
+
+func TestTxSubscriptions_Shared(t *testing.T) {
+	var (
+		client = newFakeEventsClient()
+		s      = newTxSubscriptions(client, DefaultMaxTxSubscriptions)
+		ctx    = context.Background()
+	)
+
+	first, err := s.subscribe(ctx, "HASH")
+	if err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+
+	second, err := s.subscribe(ctx, "HASH")
+	if err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+	if first != second {
+		t.Fatalf("subscribe() of the same transaction is not shared")
+	}
+
+	client.publish(first.query)
+	waitDone(t, first)
+
+	if first.event == nil || first.event.Query != first.query {
+		t.Fatalf("event = %v, want the one of %s", first.event, first.query)
+	}
+
+	s.release("HASH", first)
+	select {
+	case query := <-client.unsubscribed:
+		t.Fatalf("%s is unsubscribed while in use", query)
+	default:
+	}
+
+	s.release("HASH", second)
+	select {
+	case query := <-client.unsubscribed:
+		if query != first.query {
+			t.Fatalf("unsubscribed %s, want %s", query, first.query)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("subscription is not unsubscribed")
+	}
+}
+
+func TestTxSubscriptions_Limit(t *testing.T) {
+	var (
+		client = newFakeEventsClient()
+		s      = newTxSubscriptions(client, 2)
+		ctx    = context.Background()
+	)
+
+	first, err := s.subscribe(ctx, "A")
+	if err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+
+	second, err := s.subscribe(ctx, "B")
+	if err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+	if first.subscriber == second.subscriber {
+		t.Fatalf("subscriptions share the subscriber %s", first.subscriber)
+	}
+
+	if _, err := s.subscribe(ctx, "C"); !errors.Is(err, ErrTooManySubscriptions) {
+		t.Fatalf("subscribe() error = %v, want %v", err, ErrTooManySubscriptions)
+	}
+
+	// A wait for a transaction subscribed to already does not count.
+	if _, err := s.subscribe(ctx, "A"); err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+
+	s.release("B", second)
+	waitDone(t, second)
+
+	if second.event != nil {
+		t.Fatalf("event of a released subscription = %v", second.event)
+	}
+	if _, err := s.subscribe(ctx, "C"); err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+}
@@ -1,44 +1,54 @@
 package context
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
-	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+
+	"github.com/solarlabsteam/sentinel-api-backend/metrics"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
 )
 
-func (c Context) Tx(
-	kr keyring.Keyring, from string, gas uint64, gasAdjustment float64, gasPrices string,
-	fees string, feeGranter sdk.AccAddress, memo, signModeStr, chainID, rpcAddress string,
-	timeoutHeight uint64, simulateAndExecute bool, broadcastMode string, messages ...sdk.Msg,
-) (result *sdk.TxResponse, err error) {
-	c.Client, err = rpchttp.New(rpcAddress, "/websocket")
-	if err != nil {
-		return nil, err
+// querySequence returns the account number and the sequence of the account, preferring
+// the local sequence which accounts for the transactions not included in a block yet.
+func (c Context) querySequence(chainID, rpcAddress string, accAddr sdk.AccAddress) (accountNumber, sequence uint64, err error) {
+	if accountNumber, sequence, ok := c.sequences.peek(chainID, accAddr); ok {
+		return accountNumber, sequence, nil
 	}
 
-	key, err := kr.Key(from)
+	account, err := c.QueryAccount(rpcAddress, accAddr)
 	if err != nil {
-		return nil, err
+		return 0, 0, err
 	}
 
-	account, err := c.QueryAccount(rpcAddress, key.GetAddress())
+	return account.GetAccountNumber(), account.GetSequence(), nil
+}
+
+// buildTx builds the unsigned transaction, estimating the gas with a simulation if
+// simulateAndExecute is set, in which case the gas used by the simulation is returned.
+// The keyring is optional and only provides the public key used in the simulation.
+func (c Context) buildTx(
+	kr keyring.Keyring, accountNumber, sequence, gas uint64, gasAdjustment float64, gasPrices string, fees string,
+	feeGranter sdk.AccAddress, memo, signModeStr, chainID, rpcAddress string, timeoutHeight uint64,
+	simulateAndExecute bool, messages ...sdk.Msg,
+) (txf tx.Factory, txb client.TxBuilder, gasUsed uint64, err error) {
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
-		return nil, err
+		return txf, nil, 0, err
 	}
 
-	c.BroadcastMode = broadcastMode
-	c.ChainID = chainID
-	c.FeeGranter = feeGranter
-	c.FromName = from
-	c.Keyring = kr
-	c.NodeURI = rpcAddress
-	c.SignModeStr = signModeStr
-	c.Simulate = false
-	c.SkipConfirm = true
-
 	signMode := signing.SignMode_SIGN_MODE_UNSPECIFIED
 	switch signModeStr {
 	case flags.SignModeDirect:
@@ -47,15 +57,15 @@ func (c Context) Tx(
 		signMode = signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
 	}
 
-	txf := tx.Factory{}.
+	txf = tx.Factory{}.
 		WithTxConfig(c.TxConfig).
 		WithAccountRetriever(c.AccountRetriever).
 		WithKeybase(kr).
 		WithChainID(chainID).
 		WithGas(gas).
 		WithSimulateAndExecute(simulateAndExecute).
-		WithAccountNumber(account.GetAccountNumber()).
-		WithSequence(account.GetSequence()).
+		WithAccountNumber(accountNumber).
+		WithSequence(sequence).
 		WithTimeoutHeight(timeoutHeight).
 		WithGasAdjustment(gasAdjustment).
 		WithMemo(memo).
@@ -64,28 +74,316 @@ func (c Context) Tx(
 		WithGasPrices(gasPrices)
 
 	if txf.SimulateAndExecute() {
-		_, adjusted, err := tx.CalculateGas(c, txf, messages...)
+		simRes, adjusted, err := tx.CalculateGas(c, txf, messages...)
 		if err != nil {
-			return nil, err
+			return txf, nil, 0, types.NewErrorFromSimulation(err)
 		}
 
+		gasUsed = simRes.GasInfo.GasUsed
 		txf = txf.WithGas(adjusted)
 	}
 
-	txb, err := tx.BuildUnsignedTx(txf, messages...)
+	txb, err = tx.BuildUnsignedTx(txf, messages...)
+	if err != nil {
+		return txf, nil, 0, types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	txb.SetFeeGranter(feeGranter)
+	return txf, txb, gasUsed, nil
+}
+
+// GenerateTx builds the unsigned transaction of the given signer, to be signed by the
+// caller with the returned signer data.
+func (c Context) GenerateTx(
+	accAddr sdk.AccAddress, gas uint64, gasAdjustment float64, gasPrices string, fees string,
+	feeGranter sdk.AccAddress, memo, chainID, rpcAddress string, timeoutHeight uint64,
+	simulateAndExecute bool, messages ...sdk.Msg,
+) (sdk.Tx, *authsigning.SignerData, error) {
+	accountNumber, sequence, err := c.querySequence(chainID, rpcAddress, accAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txf, txb, _, err := c.buildTx(
+		nil, accountNumber, sequence, gas, gasAdjustment, gasPrices, fees, feeGranter, memo, "", chainID, rpcAddress,
+		timeoutHeight, simulateAndExecute, messages...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return txb.GetTx(), &authsigning.SignerData{
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+	}, nil
+}
+
+// SimulateTx estimates the gas of the messages without broadcasting them, and returns
+// the gas used by the simulation and the transaction with the adjusted gas and its fees.
+func (c Context) SimulateTx(
+	kr keyring.Keyring, accAddr sdk.AccAddress, gasAdjustment float64, gasPrices string, fees string,
+	feeGranter sdk.AccAddress, memo, chainID, rpcAddress string, timeoutHeight uint64, messages ...sdk.Msg,
+) (gasUsed uint64, result sdk.FeeTx, err error) {
+	accountNumber, sequence, err := c.querySequence(chainID, rpcAddress, accAddr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	_, txb, gasUsed, err := c.buildTx(
+		kr, accountNumber, sequence, 0, gasAdjustment, gasPrices, fees, feeGranter, memo, "", chainID, rpcAddress,
+		timeoutHeight, true, messages...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return gasUsed, txb.GetTx(), nil
+}
+
+// Tx builds, signs and broadcasts the messages, and does it again when the chain reports
+// a sequence mismatch or the RPC fails with a transient error, up to the retries of the
+// configuration. A transaction whose broadcast failed is only signed again once it is
+// found neither in a block nor in the mempool. The number of attempts made is returned
+// along with the result.
+func (c Context) Tx(
+	kr keyring.Keyring, from string, gas uint64, gasAdjustment float64, gasPrices string,
+	fees string, feeGranter sdk.AccAddress, memo, signModeStr, chainID, rpcAddress string,
+	timeoutHeight uint64, simulateAndExecute bool, broadcastMode string, messages ...sdk.Msg,
+) (result *sdk.TxResponse, attempts int64, err error) {
+	defer func() { metrics.TxAttempts.Observe(float64(attempts)) }()
+
+	backoff := c.config.TxRetryBackoff
+	for attempts = 1; ; attempts++ {
+		result, err = c.tx(
+			kr, from, gas, gasAdjustment, gasPrices, fees, feeGranter, memo, signModeStr, chainID, rpcAddress,
+			timeoutHeight, simulateAndExecute, broadcastMode, messages...,
+		)
+		if attempts > c.config.TxMaxRetries {
+			break
+		}
+		if err != nil && !isRetryableTxError(err) {
+			break
+		}
+		if err == nil && !isWrongSequence(result.Codespace, result.Code) {
+			break
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return nil, attempts, c.ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+
+	return result, attempts, err
+}
+
+func isWrongSequence(codespace string, code uint32) bool {
+	return codespace == sdkerrors.ErrWrongSequence.Codespace() && code == sdkerrors.ErrWrongSequence.ABCICode()
+}
+
+// isRetryableTxError reports whether a transaction which could not be broadcast may
+// succeed when it is built and broadcast again.
+func isRetryableTxError(err error) bool {
+	if strings.Contains(err.Error(), sdkerrors.ErrWrongSequence.Error()) {
+		return true
+	}
+
+	var v *types.Error
+	if errors.As(err, &v) {
+		return false
+	}
+
+	return isRetryableError(err)
+}
+
+func (c Context) tx(
+	kr keyring.Keyring, from string, gas uint64, gasAdjustment float64, gasPrices string,
+	fees string, feeGranter sdk.AccAddress, memo, signModeStr, chainID, rpcAddress string,
+	timeoutHeight uint64, simulateAndExecute bool, broadcastMode string, messages ...sdk.Msg,
+) (result *sdk.TxResponse, err error) {
+	c.Client, err = c.getClient(rpcAddress)
 	if err != nil {
 		return nil, err
 	}
 
-	txb.SetFeeGranter(c.GetFeeGranterAddress())
-	if err = tx.Sign(txf, c.GetFromName(), txb, true); err != nil {
+	key, err := kr.Key(from)
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	c.ChainID = chainID
+	c.FeeGranter = feeGranter
+	c.FromName = from
+	c.Keyring = kr
+	c.SignModeStr = signModeStr
+	c.Simulate = false
+	c.SkipConfirm = true
+
+	// The transactions of an account are serialized up to their broadcast, so that
+	// each one is signed with the sequence following the one of the previous.
+	item, err := c.sequences.acquire(c.ctx, chainID, key.GetAddress())
+	if err != nil {
 		return nil, err
 	}
 
+	defer c.sequences.release(item)
+
+	accountNumber, sequence, ok := c.sequences.get(item)
+	if !ok {
+		account, err := c.QueryAccount(rpcAddress, key.GetAddress())
+		if err != nil {
+			return nil, err
+		}
+
+		accountNumber, sequence = account.GetAccountNumber(), account.GetSequence()
+		c.sequences.set(item, accountNumber, sequence)
+	}
+
+	txf, txb, _, err := c.buildTx(
+		kr, accountNumber, sequence, gas, gasAdjustment, gasPrices, fees, feeGranter, memo, signModeStr, chainID,
+		rpcAddress, timeoutHeight, simulateAndExecute, messages...,
+	)
+	if err != nil {
+		c.sequences.resync(item, err.Error())
+		return nil, err
+	}
+
+	if err = tx.Sign(txf, c.GetFromName(), txb, true); err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
 	txBytes, err := c.TxConfig.TxEncoder()(txb.GetTx())
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	result, err = c.BroadcastTxBytes(rpcAddress, broadcastMode, txBytes)
+	if err != nil {
+		// The transaction may have reached the mempool regardless, in which case it
+		// must not be signed again with the next sequence.
+		if result, err = c.queryBroadcastTx(rpcAddress, txBytes, err); err != nil {
+			c.sequences.invalidate(item)
+			return nil, err
+		}
+	}
+
+	c.sequences.update(item, result)
+	return result, nil
+}
+
+// queryBroadcastTx looks for a transaction whose broadcast failed with a transient
+// error, which leaves its outcome unknown. The result is returned when the transaction
+// is in a block or in the mempool, and the broadcast error when it is in neither, so
+// that it is built and broadcast again. When the lookup fails too, the outcome remains
+// unknown and the error is one of the catalogue, which is not retried.
+func (c Context) queryBroadcastTx(rpcAddress string, txBytes []byte, broadcastErr error) (*sdk.TxResponse, error) {
+	if !isRetryableError(broadcastErr) {
+		return nil, broadcastErr
+	}
+
+	hash := fmt.Sprintf("%X", tmhash.Sum(txBytes))
+	unknownErr := func(err error) error {
+		err = fmt.Errorf("outcome of transaction %s is unknown: %s; %w", hash, broadcastErr, err)
+		return types.NewError(types.ErrorCodeRPCUnavailable, err)
+	}
+
+	txRes, err := c.QueryTx(rpcAddress, hash)
+	if err != nil {
+		return nil, unknownErr(err)
+	}
+	if txRes != nil {
+		return sdk.NewResponseResultTx(txRes, nil, ""), nil
+	}
+
+	found, err := c.QueryUnconfirmedTx(rpcAddress, hash)
+	if err != nil {
+		return nil, unknownErr(err)
+	}
+	if found {
+		return &sdk.TxResponse{TxHash: hash}, nil
+	}
+
+	return nil, broadcastErr
+}
+
+// BroadcastTxBytes broadcasts a transaction encoded in protobuf, which is signed by the
+// server or by the caller.
+func (c Context) BroadcastTxBytes(rpcAddress, broadcastMode string, txBytes []byte) (result *sdk.TxResponse, err error) {
+	c.Client, err = c.getClient(rpcAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	c.BroadcastMode = broadcastMode
+	c.NodeURI = rpcAddress
+
+	result, err = c.BroadcastTx(txBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.BroadcastTx(txBytes)
+	metrics.ObserveTxBroadcast(result.Codespace, result.Code)
+	return result, nil
+}
+
+// EncodeTxJSON encodes in protobuf a signed transaction given in protobuf JSON, or a
+// legacy StdTx given in amino JSON. A StdTx does not carry the sequences of its signers,
+// which are required in the signer infos, so they are queried from the chain.
+func (c Context) EncodeTxJSON(rpcAddress string, v []byte) ([]byte, error) {
+	result, err := c.TxConfig.TxJSONDecoder()(v)
+	if err != nil {
+		stdTx, stdErr := legacytx.StdTxConfig{Cdc: c.LegacyAmino}.TxJSONDecoder()(v)
+		if stdErr != nil {
+			return nil, types.NewError(types.ErrorCodeBadInput, err)
+		}
+
+		result, err = c.convertStdTx(rpcAddress, stdTx.(legacytx.StdTx))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	txBytes, err := c.TxConfig.TxEncoder()(result)
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	return txBytes, nil
+}
+
+func (c Context) convertStdTx(rpcAddress string, stdTx legacytx.StdTx) (sdk.Tx, error) {
+	txb := c.TxConfig.NewTxBuilder()
+	if err := tx.CopyTx(stdTx, txb, false); err != nil {
+		return nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	sigs, err := stdTx.GetSignaturesV2()
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	signers := stdTx.GetSigners()
+	if len(sigs) != len(signers) {
+		err := fmt.Errorf("expected %d signatures, got %d", len(signers), len(sigs))
+		return nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	for i := 0; i < len(signers); i++ {
+		account, err := c.QueryAccount(rpcAddress, signers[i])
+		if err != nil {
+			return nil, err
+		}
+
+		sigs[i].Sequence = account.GetSequence()
+	}
+
+	if err = txb.SetSignatures(sigs...); err != nil {
+		return nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	return txb.GetTx(), nil
 }
@@ -0,0 +1,40 @@
+package ethsecp256k1
+
+import (
+	cryptohd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+const (
+	// CoinType is the BIP44 coin type of Ethereum, with which the EVM wallets derive
+	// their keys.
+	CoinType = 60
+
+	PubKeyType = cryptohd.PubKeyType(KeyType)
+)
+
+var (
+	// EthSecp256k1 derives the keys of the EVM wallets. The derivation is the BIP32 one
+	// of secp256k1, only the keys differ.
+	EthSecp256k1 keyring.SignatureAlgo = ethSecp256k1Algo{}
+)
+
+type ethSecp256k1Algo struct{}
+
+func (ethSecp256k1Algo) Name() cryptohd.PubKeyType {
+	return PubKeyType
+}
+
+func (ethSecp256k1Algo) Derive() cryptohd.DeriveFn {
+	return cryptohd.Secp256k1.Derive()
+}
+
+func (ethSecp256k1Algo) Generate() cryptohd.GenerateFn {
+	return func(bz []byte) cryptotypes.PrivKey {
+		key := make([]byte, PrivKeySize)
+		copy(key, bz)
+
+		return &PrivKey{Key: key}
+	}
+}
@@ -0,0 +1,284 @@
+// Package ethsecp256k1 implements the eth_secp256k1 keys of the EVM compatible Cosmos
+// chains, which are secp256k1 keys with Ethereum addresses and Keccak-256 signatures.
+//
+// The keys are encoded as the ones of Ethermint, so that their armored exports can be
+// imported as is. Transactions signed with them are only accepted by the chains which
+// register these keys, which the hub does not, so they are only derived and not used
+// for signing transactions.
+package ethsecp256k1
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/tendermint/tendermint/crypto"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	KeyType     = "eth_secp256k1"
+	PrivKeySize = 32
+	PubKeySize  = 33
+
+	PrivKeyName = "ethermint/PrivKeyEthSecp256k1"
+	PubKeyName  = "ethermint/PubKeyEthSecp256k1"
+
+	privKeyMessageName = "ethermint.crypto.v1.ethsecp256k1.PrivKey"
+	pubKeyMessageName  = "ethermint.crypto.v1.ethsecp256k1.PubKey"
+
+	// signatureSize is the size of the R || S || V signatures.
+	signatureSize = 65
+)
+
+var (
+	_ cryptotypes.PrivKey  = &PrivKey{}
+	_ cryptotypes.PubKey   = &PubKey{}
+	_ codec.AminoMarshaler = &PrivKey{}
+	_ codec.AminoMarshaler = &PubKey{}
+)
+
+func init() {
+	// The keyring of the SDK stores its keys with the legacy Amino codec.
+	RegisterLegacyAminoCodec(legacy.Cdc)
+}
+
+func RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
+	cdc.RegisterConcrete(&PubKey{}, PubKeyName, nil)
+	cdc.RegisterConcrete(&PrivKey{}, PrivKeyName, nil)
+}
+
+func RegisterInterfaces(registry codectypes.InterfaceRegistry) {
+	registry.RegisterImplementations((*cryptotypes.PubKey)(nil), &PubKey{})
+	registry.RegisterImplementations((*cryptotypes.PrivKey)(nil), &PrivKey{})
+}
+
+func keccak256(data ...[]byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	for _, v := range data {
+		hash.Write(v)
+	}
+
+	return hash.Sum(nil)
+}
+
+// marshalKey encodes the key as the single bytes field of the protobuf messages of
+// the keys.
+func marshalKey(key []byte) []byte {
+	buf := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(buf, key)
+}
+
+func unmarshalKey(buf []byte) ([]byte, error) {
+	var key []byte
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		if num == 1 && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(buf)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+
+			key, buf = append([]byte{}, v...), buf[n:]
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, buf)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+	}
+
+	return key, nil
+}
+
+type PrivKey struct {
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (k *PrivKey) Reset() {
+	*k = PrivKey{}
+}
+
+func (k *PrivKey) String() string {
+	return fmt.Sprintf("EthPrivKeySecp256k1{%X}", k.PubKey().Bytes())
+}
+
+func (*PrivKey) ProtoMessage() {}
+
+func (*PrivKey) XXX_MessageName() string {
+	return privKeyMessageName
+}
+
+func (k *PrivKey) Marshal() ([]byte, error) {
+	return marshalKey(k.Key), nil
+}
+
+func (k PrivKey) MarshalAmino() ([]byte, error) {
+	return k.Key, nil
+}
+
+func (k *PrivKey) Bytes() []byte {
+	return k.Key
+}
+
+func (k *PrivKey) Type() string {
+	return KeyType
+}
+
+func (k PrivKey) MarshalAminoJSON() ([]byte, error) {
+	return k.MarshalAmino()
+}
+
+func (k *PrivKey) UnmarshalAminoJSON(bz []byte) error {
+	return k.UnmarshalAmino(bz)
+}
+
+func (k *PrivKey) Unmarshal(buf []byte) (err error) {
+	k.Key, err = unmarshalKey(buf)
+	return err
+}
+
+func (k *PrivKey) UnmarshalAmino(bz []byte) error {
+	if len(bz) != PrivKeySize {
+		return fmt.Errorf("invalid privkey size %d", len(bz))
+	}
+
+	k.Key = bz
+	return nil
+}
+
+func (k *PrivKey) PubKey() cryptotypes.PubKey {
+	key := secp256k1.PrivKeyFromBytes(k.Key)
+	return &PubKey{Key: key.PubKey().SerializeCompressed()}
+}
+
+func (k *PrivKey) Equals(other cryptotypes.LedgerPrivKey) bool {
+	return k.Type() == other.Type() && subtle.ConstantTimeCompare(k.Bytes(), other.Bytes()) == 1
+}
+
+// Sign returns the R || S || V signature of the Keccak-256 hash of the message, as
+// Ethereum does.
+func (k *PrivKey) Sign(msg []byte) ([]byte, error) {
+	if len(k.Key) != PrivKeySize {
+		return nil, fmt.Errorf("invalid privkey size %d", len(k.Key))
+	}
+
+	// The compact signature is V || R || S, with V being 27 plus the recovery ID.
+	sig := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(k.Key), keccak256(msg), false)
+	return append(sig[1:], sig[0]-27), nil
+}
+
+type PubKey struct {
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (k *PubKey) Reset() {
+	*k = PubKey{}
+}
+
+func (k *PubKey) String() string {
+	return fmt.Sprintf("EthPubKeySecp256k1{%X}", k.Key)
+}
+
+func (*PubKey) ProtoMessage() {}
+
+func (*PubKey) XXX_MessageName() string {
+	return pubKeyMessageName
+}
+
+func (k *PubKey) Marshal() ([]byte, error) {
+	return marshalKey(k.Key), nil
+}
+
+func (k PubKey) MarshalAmino() ([]byte, error) {
+	return k.Key, nil
+}
+
+func (k *PubKey) Bytes() []byte {
+	return k.Key
+}
+
+func (k *PubKey) Type() string {
+	return KeyType
+}
+
+func (k PubKey) MarshalAminoJSON() ([]byte, error) {
+	return k.MarshalAmino()
+}
+
+func (k *PubKey) UnmarshalAminoJSON(bz []byte) error {
+	return k.UnmarshalAmino(bz)
+}
+
+func (k *PubKey) Unmarshal(buf []byte) (err error) {
+	k.Key, err = unmarshalKey(buf)
+	return err
+}
+
+func (k *PubKey) UnmarshalAmino(bz []byte) error {
+	if len(bz) != PubKeySize {
+		return fmt.Errorf("invalid pubkey size %d", len(bz))
+	}
+
+	k.Key = bz
+	return nil
+}
+
+// Address returns the Ethereum address of the key, which is the last 20 bytes of the
+// Keccak-256 hash of the uncompressed key.
+func (k *PubKey) Address() crypto.Address {
+	key, err := secp256k1.ParsePubKey(k.Key)
+	if err != nil {
+		panic(err)
+	}
+
+	return crypto.Address(keccak256(key.SerializeUncompressed()[1:])[12:])
+}
+
+func (k *PubKey) Equals(other cryptotypes.PubKey) bool {
+	return k.Type() == other.Type() && bytes.Equal(k.Bytes(), other.Bytes())
+}
+
+// VerifySignature verifies an R || S signature, with or without its V byte, of the
+// Keccak-256 hash of the message. Signatures with a high S are rejected.
+func (k *PubKey) VerifySignature(msg, sig []byte) bool {
+	return k.verifyHash(keccak256(msg), sig)
+}
+
+func (k *PubKey) verifyHash(hash, sig []byte) bool {
+	if len(sig) == signatureSize {
+		sig = sig[:signatureSize-1]
+	}
+	if len(sig) != signatureSize-1 {
+		return false
+	}
+
+	key, err := secp256k1.ParsePubKey(k.Key)
+	if err != nil {
+		return false
+	}
+
+	var r, s secp256k1.ModNScalar
+	if overflow := r.SetByteSlice(sig[:32]); overflow || r.IsZero() {
+		return false
+	}
+	if overflow := s.SetByteSlice(sig[32:]); overflow || s.IsZero() || s.IsOverHalfOrder() {
+		return false
+	}
+
+	return ecdsa.NewSignature(&r, &s).Verify(hash, key)
+}
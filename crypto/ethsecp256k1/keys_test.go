@@ -0,0 +1,229 @@
+package ethsecp256k1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+
+	return buf
+}
+
+// The keys and addresses of the Ethereum test vectors, which Ethermint derives alike.
+func TestPrivKey_Address(t *testing.T) {
+	tests := []struct {
+		privKey string
+		address string
+	}{
+		{"289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032", "970e8128ab834e8eac17ab8e3812f010678cf791"},
+		{"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318", "2c7536e3605d9c16a7a3d7b1898e529396a65c23"},
+	}
+
+	for _, tt := range tests {
+		key := &PrivKey{Key: mustDecodeHex(t, tt.privKey)}
+		if got := hex.EncodeToString(key.PubKey().Address()); got != tt.address {
+			t.Errorf("Address() = %s, want %s", got, tt.address)
+		}
+	}
+}
+
+func TestPrivKey_PubKey(t *testing.T) {
+	key := &PrivKey{Key: mustDecodeHex(t, "289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032")}
+
+	want := "037db227d7094ce215c3a0f57e1bcc732551fe351f94249471934567e0f5dc1bf7"
+	if got := hex.EncodeToString(key.PubKey().Bytes()); got != want {
+		t.Fatalf("PubKey() = %s, want %s", got, want)
+	}
+}
+
+// The signature of the go-ethereum test vector, whose message is a Keccak-256 hash.
+func TestPubKey_VerifyHash(t *testing.T) {
+	var (
+		hash   = mustDecodeHex(t, "ce0677bb30baa8cf067c88db9811f4333d131bf8bcf12fe7065d211dce971008")
+		sig    = mustDecodeHex(t, "90f27b8b488db00b00606796d2987f6a5f59ae62ea05effe84fef5b8b0e549984a691139ad57a3f0b906637673aa2f63d1f55cb1a69199d4009eea23ceaddc9301")
+		pubKey = &PubKey{Key: mustDecodeHex(t, "02e32df42865e97135acfb65f3bae71bdc86f4d49150ad6a440b6f15878109880a")}
+	)
+
+	if !pubKey.verifyHash(hash, sig) {
+		t.Fatalf("verifyHash() = false")
+	}
+	if !pubKey.verifyHash(hash, sig[:64]) {
+		t.Fatalf("verifyHash() without V = false")
+	}
+
+	invalid := append([]byte{}, sig...)
+	invalid[0] ^= 0x01
+	if pubKey.verifyHash(hash, invalid) {
+		t.Fatalf("verifyHash() of an altered signature = true")
+	}
+}
+
+func TestPrivKey_Sign(t *testing.T) {
+	var (
+		key    = &PrivKey{Key: mustDecodeHex(t, "289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032")}
+		pubKey = key.PubKey()
+		msg    = []byte("message")
+	)
+
+	sig, err := key.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if len(sig) != signatureSize {
+		t.Fatalf("Sign() length = %d, want %d", len(sig), signatureSize)
+	}
+	if v := sig[signatureSize-1]; v > 1 {
+		t.Fatalf("Sign() V = %d, want 0 or 1", v)
+	}
+	if !pubKey.VerifySignature(msg, sig) {
+		t.Fatalf("VerifySignature() = false")
+	}
+	if pubKey.VerifySignature([]byte("other"), sig) {
+		t.Fatalf("VerifySignature() of another message = true")
+	}
+
+	// The signatures are deterministic, as the ones of go-ethereum.
+	other, err := key.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if !bytes.Equal(sig, other) {
+		t.Fatalf("Sign() is not deterministic")
+	}
+
+	if _, err := (&PrivKey{Key: []byte{0x01}}).Sign(msg); err == nil {
+		t.Fatalf("Sign() with an invalid key error = nil")
+	}
+}
+
+func TestAmino(t *testing.T) {
+	var (
+		key    = &PrivKey{Key: mustDecodeHex(t, "289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032")}
+		pubKey = key.PubKey()
+	)
+
+	// The keys are prefixed with the ones of their Ethermint names, followed by their
+	// length prefixed bytes.
+	tests := []struct {
+		key    interface{}
+		prefix string
+		ptr    interface{}
+	}{
+		{key, "fcd2efcc20", &PrivKey{}},
+		{pubKey, "f3b3cd0321", &PubKey{}},
+	}
+
+	for _, tt := range tests {
+		bz, err := legacy.Cdc.Marshal(tt.key)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		if got := hex.EncodeToString(bz); !strings.HasPrefix(got, tt.prefix) {
+			t.Fatalf("Marshal() = %s, want prefix %s", got, tt.prefix)
+		}
+
+		if err := legacy.Cdc.Unmarshal(bz, tt.ptr); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		v := tt.ptr.(interface{ Bytes() []byte })
+		if !bytes.Equal(v.Bytes(), tt.key.(interface{ Bytes() []byte }).Bytes()) {
+			t.Fatalf("Unmarshal() = %X, want %X", v.Bytes(), tt.key)
+		}
+	}
+
+	bz, err := legacy.Cdc.MarshalJSON(pubKey)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	want := `{"type":"ethermint/PubKeyEthSecp256k1","value":"A32yJ9cJTOIVw6D1fhvMcyVR/jUflCSUcZNFZ+D13Bv3"}`
+	if string(bz) != want {
+		t.Fatalf("MarshalJSON() = %s, want %s", bz, want)
+	}
+
+	var v cryptotypes.PubKey
+	if err := legacy.Cdc.UnmarshalJSON(bz, &v); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !v.Equals(pubKey) {
+		t.Fatalf("UnmarshalJSON() = %v, want %v", v, pubKey)
+	}
+}
+
+func TestProto(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	RegisterInterfaces(registry)
+
+	var (
+		cdc    = codec.NewProtoCodec(registry)
+		pubKey = (&PrivKey{Key: mustDecodeHex(t, "289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032")}).PubKey()
+	)
+
+	any, err := codectypes.NewAnyWithValue(pubKey)
+	if err != nil {
+		t.Fatalf("NewAnyWithValue() error = %v", err)
+	}
+	if want := "/ethermint.crypto.v1.ethsecp256k1.PubKey"; any.TypeUrl != want {
+		t.Fatalf("TypeUrl = %s, want %s", any.TypeUrl, want)
+	}
+	if want := "0a21" + hex.EncodeToString(pubKey.Bytes()); hex.EncodeToString(any.Value) != want {
+		t.Fatalf("Value = %X, want %s", any.Value, want)
+	}
+
+	bz, err := cdc.MarshalInterface(pubKey)
+	if err != nil {
+		t.Fatalf("MarshalInterface() error = %v", err)
+	}
+
+	var v cryptotypes.PubKey
+	if err := cdc.UnmarshalInterface(bz, &v); err != nil {
+		t.Fatalf("UnmarshalInterface() error = %v", err)
+	}
+	if !v.Equals(pubKey) {
+		t.Fatalf("UnmarshalInterface() = %v, want %v", v, pubKey)
+	}
+
+	bz, err = cdc.MarshalInterfaceJSON(pubKey)
+	if err != nil {
+		t.Fatalf("MarshalInterfaceJSON() error = %v", err)
+	}
+
+	want := `{"@type":"/ethermint.crypto.v1.ethsecp256k1.PubKey","key":"A32yJ9cJTOIVw6D1fhvMcyVR/jUflCSUcZNFZ+D13Bv3"}`
+	if string(bz) != want {
+		t.Fatalf("MarshalInterfaceJSON() = %s, want %s", bz, want)
+	}
+
+	v = nil
+	if err := cdc.UnmarshalInterfaceJSON(bz, &v); err != nil {
+		t.Fatalf("UnmarshalInterfaceJSON() error = %v", err)
+	}
+	if !v.Equals(pubKey) {
+		t.Fatalf("UnmarshalInterfaceJSON() = %v, want %v", v, pubKey)
+	}
+
+	// Unknown fields are skipped.
+	var key PubKey
+	if err := key.Unmarshal(append([]byte{0x10, 0x01}, any.Value...)); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !key.Equals(pubKey) {
+		t.Fatalf("Unmarshal() = %v, want %v", &key, pubKey)
+	}
+}
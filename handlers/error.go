@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+// abortWithError responds with the catalogue entry of the error and its HTTP status code.
+func abortWithError(c *gin.Context, err error) {
+	v := types.AsError(err)
+	c.AbortWithStatusJSON(v.StatusCode(), types.NewResponseError(v))
+}
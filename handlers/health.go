@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+type readinessEndpoint struct {
+	context.Endpoint
+	Stalled bool `json:"stalled"`
+}
+
+// HandlerReadiness probes the configured RPC endpoints and reports the instance as ready
+// when at least one of them responds, is not catching up and has a recent enough block.
+func HandlerReadiness(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx.Endpoints().Probe(c.Request.Context())
+
+		var (
+			ready  bool
+			now    = time.Now()
+			result []readinessEndpoint
+		)
+
+		for _, item := range ctx.Endpoints().Endpoints() {
+			v := readinessEndpoint{
+				Endpoint: item,
+				Stalled:  item.Error == "" && now.Sub(item.BlockTime) > ctx.Config().MaxBlockAge,
+			}
+
+			if item.Healthy && !v.Stalled {
+				ready = true
+			}
+
+			result = append(result, v)
+		}
+
+		if !ready {
+			err := fmt.Errorf("none of the rpc endpoints is ready")
+			c.JSON(http.StatusServiceUnavailable, types.NewResponse(types.NewError(types.ErrorCodeRPCUnavailable, err), result))
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
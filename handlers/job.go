@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/jobs"
+	"github.com/solarlabsteam/sentinel-api-backend/requests"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+// The stages reported by the jobs.
+const (
+	jobStageBroadcasting = "broadcasting"
+	jobStageWaiting      = "waiting_for_inclusion"
+	jobStageKeyExchange  = "exchanging_session_key"
+)
+
+// noProgress is the progress function of the flows which do not run as a job.
+func noProgress(string) {}
+
+// submitJob queues the flow as a job. The flow is given a copy of the context bound
+// to the job instead of the request, which ends once the job ID is returned.
+func submitJob(ctx context.Context, fn func(ctx context.Context, progress func(stage string)) (interface{}, error)) (*jobs.Job, error) {
+	job, err := ctx.Jobs().Submit(func(jobCtx gocontext.Context, progress func(stage string)) (interface{}, error) {
+		return fn(ctx.WithContext(jobCtx), progress)
+	})
+	if errors.Is(err, jobs.ErrQueueFull) {
+		return nil, types.NewError(types.ErrorCodeQueueFull, err)
+	}
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	return job, nil
+}
+
+// resultStatusCode returns 202 for a job which was queued, and 200 for a result.
+func resultStatusCode(v interface{}) int {
+	if _, ok := v.(*jobs.Job); ok {
+		return http.StatusAccepted
+	}
+
+	return http.StatusOK
+}
+
+func HandlerGetJob(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestGetJob(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		result, ok := ctx.Jobs().Get(req.URI.ID)
+		if !ok {
+			err := fmt.Errorf("job %s does not exist", req.URI.ID)
+			abortWithError(c, types.NewError(types.ErrorCodeNotFound, err))
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"bytes"
+	gocontext "context"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"time"
 
+	cryptohd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gin-gonic/gin"
 	"github.com/go-kit/kit/transport/http/jsonrpc"
@@ -17,14 +21,27 @@ import (
 	sessiontypes "github.com/sentinel-official/hub/x/session/types"
 
 	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/metrics"
 	"github.com/solarlabsteam/sentinel-api-backend/requests"
 	"github.com/solarlabsteam/sentinel-api-backend/responses"
 	"github.com/solarlabsteam/sentinel-api-backend/types"
 	"github.com/solarlabsteam/sentinel-api-backend/utils"
-	eventutils "github.com/solarlabsteam/sentinel-api-backend/utils/event"
+	"github.com/solarlabsteam/sentinel-api-backend/webhooks"
 )
 
-func fetchNodeInfo(remoteURL string) (map[string]interface{}, error) {
+// nodeResponse is the response of the node API, which has its own numeric error codes.
+type nodeResponse struct {
+	Success bool `json:"success"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+}
+
+func fetchNodeInfo(ctx gocontext.Context, remoteURL string, timeout time.Duration) (_ map[string]interface{}, err error) {
+	defer func(start time.Time) { metrics.ObserveNodeCall("status", start, err) }(time.Now())
+
 	endpoint, err := url.JoinPath(remoteURL, "status")
 	if err != nil {
 		return nil, err
@@ -36,214 +53,331 @@ func fetchNodeInfo(remoteURL string) (map[string]interface{}, error) {
 				InsecureSkipVerify: true,
 			},
 		},
-		Timeout: 15 * time.Second,
+		Timeout: timeout,
 	}
 
-	resp, err := client.Get(endpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		if err = resp.Body.Close(); err != nil {
-			panic(err)
-		}
-	}()
-
-	var body types.Response
-	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+	resp, err := client.Do(req)
+	if err != nil {
 		return nil, err
 	}
 
-	return body.Result.(map[string]interface{}), nil
-}
-
-func HandlerAddSessionKey(ctx context.Context) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		req, err := requests.NewRequestAddSessionKey(c)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
-			return
-		}
+	defer resp.Body.Close()
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
-			return
-		}
+	var body nodeResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
 
-		var (
-			accAddress = key.GetAddress()
-			messages   []sdk.Msg
-		)
+	result, ok := body.Result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid status result %v", body.Result)
+	}
 
-		rSession, err := ctx.QueryActiveSession(req.Query.RPCAddress, accAddress)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
-			return
-		}
+	return result, nil
+}
 
-		if rSession != nil {
-			messages = append(
-				messages,
-				sessiontypes.NewMsgEndRequest(
-					accAddress,
-					rSession.ID,
-					0,
-				),
-			)
-		}
+// addSessionKey ends the active session of the account if any, starts a session on the
+// node and sends the key of the client to the node, which adds it for the session.
+func addSessionKey(
+	ctx context.Context, progress func(stage string), req *requests.RequestAddSessionKey,
+	kr keyring.Keyring, key keyring.Info,
+) (*responses.ResponseAddSessionKey, error) {
+	var (
+		accAddress = key.GetAddress()
+		messages   []sdk.Msg
+	)
+
+	rSession, err := ctx.QueryActiveSession(req.Query.RPCAddress, accAddress)
+	if err != nil {
+		return nil, err
+	}
 
+	if rSession != nil {
 		messages = append(
 			messages,
-			sessiontypes.NewMsgStartRequest(
+			sessiontypes.NewMsgEndRequest(
 				accAddress,
-				req.URI.ID,
-				req.NodeAddress,
+				rSession.ID,
+				0,
 			),
 		)
+	}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+	messages = append(
+		messages,
+		sessiontypes.NewMsgStartRequest(
+			accAddress,
+			req.URI.ID,
+			req.NodeAddress,
+		),
+	)
+
+	txRes, err := broadcastTx(ctx, progress, kr, key.GetName(), &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
+	if err != nil {
+		return nil, err
+	}
+
+	progress(jobStageKeyExchange)
+
+	if len(txRes.SessionIDs) == 0 {
+		err := fmt.Errorf("transaction %s has not started a session", txRes.Hash)
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	sessionID := txRes.SessionIDs[0]
+
+	rNode, err := ctx.QueryNode(req.Query.RPCAddress, req.NodeAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	rNodeInfo, err := fetchNodeInfo(ctx.RequestContext(), rNode.RemoteURL, ctx.Config().NodeTimeout)
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeNodeUnreachable, err)
+	}
+
+	nodeType, ok := rNodeInfo["type"].(float64)
+	if !ok {
+		err := fmt.Errorf("invalid node type %v", rNodeInfo["type"])
+		return nil, types.NewError(types.ErrorCodeNodeUnreachable, err)
+	}
+
+	var (
+		clientKey    string
+		wgPrivateKey *types.Key
+		uid          []byte
+	)
+
+	if nodeType == 1 {
+		wgPrivateKey, err = types.NewPrivateKey()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
+			return nil, types.NewError(types.ErrorCodeInternal, err)
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		clientKey = wgPrivateKey.Public().String()
+	} else if nodeType == 2 {
+		uid, err = uuid.GenerateRandomBytes(16)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(5, err))
-			return
+			return nil, types.NewError(types.ErrorCodeInternal, err)
 		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(5, err))
-			return
+
+		clientKey = base64.StdEncoding.EncodeToString(append([]byte{0x01}, uid...))
+	} else {
+		err := fmt.Errorf("unknown node type %f", nodeType)
+		return nil, types.NewError(types.ErrorCodeNodeUnreachable, err)
+	}
+
+	signature, _, err := kr.Sign(key.GetName(), sdk.Uint64ToBigEndian(sessionID))
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	nReq, err := json.Marshal(
+		map[string]interface{}{
+			"key":       clientKey,
+			"signature": signature,
+		},
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	endpoint, err := url.JoinPath(rNode.RemoteURL, fmt.Sprintf("/accounts/%s/sessions/%d", accAddress, sessionID))
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	var (
+		body   nodeResponse
+		client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			},
+			Timeout: ctx.Config().NodeTimeout,
 		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(5, err))
+	)
+
+	nHTTPReq, err := http.NewRequestWithContext(ctx.RequestContext(), http.MethodPost, endpoint, bytes.NewBuffer(nReq))
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	nHTTPReq.Header.Set("Content-Type", jsonrpc.ContentType)
+
+	tStart := time.Now()
+
+	resp, err := client.Do(nHTTPReq)
+	metrics.ObserveNodeCall("session_key", tStart, err)
+	if err != nil {
+		err := fmt.Errorf("error %s; time took %s", err, time.Since(tStart))
+		return nil, types.NewError(types.ErrorCodeNodeUnreachable, err)
+	}
+
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, types.NewError(types.ErrorCodeNodeUnreachable, err)
+	}
+	if body.Error != nil {
+		err := fmt.Errorf("node responded with code %d and message %s", body.Error.Code, body.Error.Message)
+		return nil, types.NewError(types.ErrorCodeNodeRejectedKey, err)
+	}
+
+	bodyResult, ok := body.Result.(string)
+	if !ok {
+		err := fmt.Errorf("invalid node result %v", body.Result)
+		return nil, types.NewError(types.ErrorCodeNodeUnreachable, err)
+	}
+
+	result := &responses.ResponseAddSessionKey{
+		NodeType: nodeType,
+		Result:   bodyResult,
+	}
+
+	if nodeType == 1 {
+		result.PrivateKey = wgPrivateKey.String()
+	} else if nodeType == 2 {
+		uid, _ := uuid.FormatUUID(uid)
+		result.UID = uid
+	}
+
+	ctx.Webhooks().Publish(
+		webhooks.EventSessionKeyAccepted,
+		req.Body.CallbackURL,
+		&webhooks.SessionKeyAccepted{
+			TxHash:      txRes.Hash,
+			SessionID:   sessionID,
+			AccAddress:  accAddress.String(),
+			NodeAddress: req.NodeAddress.String(),
+			NodeType:    nodeType,
+		},
+	)
+
+	return result, nil
+}
+
+func HandlerAddSessionKey(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestAddSessionKey(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		sessionID, err := eventutils.GetSessionIDFromABCIEvents(txRes.TxResult.Events)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(6, err))
+		if req.Query.GenerateOnly || req.Query.SimulateOnly {
+			err := errors.New("generate and simulate only modes are not supported, the session key is signed by the server")
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		rNode, err := ctx.QueryNode(req.Query.RPCAddress, req.NodeAddress)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(7, err))
+			abortWithError(c, err)
 			return
 		}
 
-		rNodeInfo, err := fetchNodeInfo(rNode.RemoteURL)
+		key, err := kr.KeyByAddress(accAddr)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(8, err))
+			abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 			return
 		}
 
-		var (
-			nodeType     = rNodeInfo["type"].(float64)
-			clientKey    string
-			wgPrivateKey *types.Key
-			uid          []byte
-		)
-
-		if nodeType == 1 {
-			wgPrivateKey, err = types.NewPrivateKey()
-			if err != nil {
-				return
-			}
-
-			clientKey = wgPrivateKey.Public().String()
-		} else if nodeType == 2 {
-			uid, err = uuid.GenerateRandomBytes(16)
+		if req.Query.Async {
+			job, err := submitJob(ctx, func(ctx context.Context, progress func(stage string)) (interface{}, error) {
+				return addSessionKey(ctx, progress, req, kr, key)
+			})
 			if err != nil {
+				abortWithError(c, err)
 				return
 			}
 
-			clientKey = base64.StdEncoding.EncodeToString(append([]byte{0x01}, uid...))
-		} else {
-			err := fmt.Errorf("unknown node type %f", nodeType)
-			c.JSON(http.StatusBadRequest, types.NewResponseError(9, err))
+			c.JSON(http.StatusAccepted, types.NewResponseResult(job))
 			return
 		}
 
-		signature, _, err := kr.Sign(key.GetName(), sdk.Uint64ToBigEndian(sessionID))
+		result, err := addSessionKey(ctx, noProgress, req, kr, key)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(10, err))
+			abortWithError(c, err)
 			return
 		}
 
-		nReq, err := json.Marshal(
-			map[string]interface{}{
-				"key":       clientKey,
-				"signature": signature,
-			},
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(11, err))
-			return
-		}
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
 
-		endpoint, err := url.JoinPath(rNode.RemoteURL, fmt.Sprintf("/accounts/%s/sessions/%d", accAddress, sessionID))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(12, err))
-			return
-		}
+// deriveKey returns the key of the algorithm derived from the mnemonic at the path.
+func deriveKey(algo, mnemonic, bip39Password string, coinType, account, index uint32) (*responses.ResponseKey, error) {
+	_, key, err := utils.NewInMemoryKey(mnemonic, coinType, account, index, bip39Password, algo)
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
 
-		var (
-			body   types.Response
-			client = &http.Client{
-				Transport: &http.Transport{
-					TLSClientConfig: &tls.Config{
-						InsecureSkipVerify: true,
-					},
-				},
-				Timeout: 15 * time.Second,
-			}
-		)
+	result, err := responses.NewResponseKey(key, cryptohd.CreateHDPath(coinType, account, index))
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
 
-		tStart := time.Now()
+	return result, nil
+}
 
-		resp, err := client.Post(endpoint, jsonrpc.ContentType, bytes.NewBuffer(nReq))
+func HandlerDeriveKeys(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestDeriveKeys(c)
 		if err != nil {
-			err := fmt.Errorf("error %s; time took %s", err, time.Since(tStart))
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(13, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		defer func() {
-			if err = resp.Body.Close(); err != nil {
-				panic(err)
+		result := make([]*responses.ResponseKey, 0, req.Query.Count)
+		for i := uint32(0); i < req.Query.Count; i++ {
+			key, err := deriveKey(
+				req.Query.Algo, req.Body.Mnemonic, req.Body.BIP39Password,
+				req.Query.CoinType, req.Query.Account, req.Query.Index+i,
+			)
+			if err != nil {
+				abortWithError(c, err)
+				return
 			}
-		}()
 
-		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(14, err))
+			result = append(result, key)
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
+
+func HandlerGenerateKey(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestGenerateKey(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
-		if body.Error != nil {
-			err := fmt.Errorf("node responded with code %d and message %s", body.Error.Code, body.Error.Message)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(15, err))
+
+		mnemonic, err := utils.NewMnemonic(req.Query.EntropyBits)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 			return
 		}
 
-		result := &responses.ResponseAddSessionKey{
-			NodeType: nodeType,
-			Result:   body.Result.(string),
+		key, err := deriveKey(req.Query.Algo, mnemonic, "", req.Query.CoinType, req.Query.Account, req.Query.Index)
+		if err != nil {
+			abortWithError(c, err)
+			return
 		}
 
-		if nodeType == 1 {
-			result.PrivateKey = wgPrivateKey.String()
-		} else if nodeType == 2 {
-			uid, _ := uuid.FormatUUID(uid)
-			result.UID = uid
+		result := &responses.ResponseGenerateKey{
+			Mnemonic: mnemonic,
+			Key:      key,
 		}
 
 		c.JSON(http.StatusOK, types.NewResponseResult(result))
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/keystore"
+	"github.com/solarlabsteam/sentinel-api-backend/requests"
+	"github.com/solarlabsteam/sentinel-api-backend/responses"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+// newKeystoreError returns the entry of the error catalogue for an error of the keystore.
+func newKeystoreError(err error) *types.Error {
+	switch {
+	case errors.Is(err, keystore.ErrKeyNotFound):
+		return types.NewError(types.ErrorCodeNotFound, err)
+	case errors.Is(err, keystore.ErrKeyLocked):
+		return types.NewError(types.ErrorCodeKeyLocked, err)
+	case errors.Is(err, keystore.ErrBadPassphrase):
+		return types.NewError(types.ErrorCodeUnauthorized, err)
+	case errors.Is(err, keystore.ErrTooManyFails):
+		return types.NewError(types.ErrorCodeTooManyAttempts, err)
+	case errors.Is(err, keystore.ErrDisabled),
+		errors.Is(err, keystore.ErrKeyExists),
+		errors.Is(err, keystore.ErrInvalidName):
+		return types.NewError(types.ErrorCodeBadInput, err)
+	default:
+		return types.NewError(types.ErrorCodeInternal, err)
+	}
+}
+
+func HandlerGetKeystoreKeys(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		items, err := ctx.Keystore().List()
+		if err != nil {
+			abortWithError(c, newKeystoreError(err))
+			return
+		}
+
+		result := make([]*responses.ResponseKeystoreKey, 0, len(items))
+		for _, item := range items {
+			result = append(result, responses.NewResponseKeystoreKey(item, ctx.Keystore().IsUnlocked(item.Name)))
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
+
+func HandlerAddKeystoreKey(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestAddKeystoreKey(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		key, mnemonic, err := ctx.Keystore().Add(
+			req.Body.Name, req.Body.Mnemonic, req.Body.BIP39Password, req.Query.Algo,
+			req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.Passphrase,
+		)
+		if err != nil {
+			abortWithError(c, newKeystoreError(err))
+			return
+		}
+
+		result := responses.NewResponseKeystoreKey(key, false)
+		result.Mnemonic = mnemonic
+
+		c.JSON(http.StatusCreated, types.NewResponseResult(result))
+	}
+}
+
+func HandlerDeleteKeystoreKey(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestKeystoreKey(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		if err := ctx.Keystore().Delete(req.URI.Name); err != nil {
+			abortWithError(c, newKeystoreError(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(nil))
+	}
+}
+
+func HandlerUnlockKeystoreKey(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestUnlockKeystoreKey(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		if err := ctx.Keystore().Unlock(req.URI.Name, req.Body.Passphrase); err != nil {
+			abortWithError(c, newKeystoreError(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(nil))
+	}
+}
+
+func HandlerLockKeystoreKey(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestKeystoreKey(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		if err := ctx.Keystore().Lock(req.URI.Name); err != nil {
+			abortWithError(c, newKeystoreError(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(nil))
+	}
+}
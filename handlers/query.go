@@ -3,37 +3,41 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/solarlabsteam/sentinel-api-backend/context"
 	"github.com/solarlabsteam/sentinel-api-backend/requests"
+	"github.com/solarlabsteam/sentinel-api-backend/responses"
 	"github.com/solarlabsteam/sentinel-api-backend/types"
 )
 
 func HandlerGetAccount(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetAccount(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryAccount(req.Query.RPCAddress, req.AccAddress)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
 		buf, err := ctx.Codec.MarshalJSON(result)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 			return
 		}
 
 		var item interface{}
 		if err := json.Unmarshal(buf, &item); err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 			return
 		}
 
@@ -43,15 +47,17 @@ func HandlerGetAccount(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetBalancesForAccount(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetBalancesForAccount(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryBalances(req.Query.RPCAddress, req.AccAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -61,15 +67,17 @@ func HandlerGetBalancesForAccount(ctx context.Context) gin.HandlerFunc {
 
 func HandlerFeegrantAllowancesByGranter(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestFeegrantAllowancesByGranter(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryFeegrantAllowancesByGranter(req.Query.RPCAddress, req.AccAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -77,13 +85,13 @@ func HandlerFeegrantAllowancesByGranter(ctx context.Context) gin.HandlerFunc {
 		for i := 0; i < len(result); i++ {
 			buf, err := ctx.Codec.MarshalJSON(result[i])
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+				abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 				return
 			}
 
 			var item interface{}
 			if err := json.Unmarshal(buf, &item); err != nil {
-				c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+				abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 				return
 			}
 
@@ -96,15 +104,17 @@ func HandlerFeegrantAllowancesByGranter(ctx context.Context) gin.HandlerFunc {
 
 func HandlerFeegrantAllowances(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestFeegrantAllowances(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryFeegrantAllowances(req.Query.RPCAddress, req.AccAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -112,13 +122,13 @@ func HandlerFeegrantAllowances(ctx context.Context) gin.HandlerFunc {
 		for i := 0; i < len(result); i++ {
 			buf, err := ctx.Codec.MarshalJSON(result[i])
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+				abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 				return
 			}
 
 			var item interface{}
 			if err := json.Unmarshal(buf, &item); err != nil {
-				c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+				abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
 				return
 			}
 
@@ -131,15 +141,17 @@ func HandlerFeegrantAllowances(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetSessionsForAccount(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetSessionsForAccount(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QuerySessionsForAccount(req.Query.RPCAddress, req.AccAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -149,15 +161,17 @@ func HandlerGetSessionsForAccount(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetSubscriptionsForAccount(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetSubscriptionsForAccount(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QuerySubscriptionsForAccount(req.Query.RPCAddress, req.AccAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -167,15 +181,17 @@ func HandlerGetSubscriptionsForAccount(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetDeposits(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetDeposits(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryDeposits(req.Query.RPCAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -185,15 +201,17 @@ func HandlerGetDeposits(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetDeposit(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetDeposit(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryDeposit(req.Query.RPCAddress, req.AccAddress)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -203,15 +221,17 @@ func HandlerGetDeposit(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetNodes(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetNodes(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryNodes(req.Query.RPCAddress, req.Status, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -221,15 +241,17 @@ func HandlerGetNodes(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetNode(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetNode(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryNode(req.Query.RPCAddress, req.NodeAddress)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -239,15 +261,17 @@ func HandlerGetNode(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetPlans(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetPlans(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryPlans(req.Query.RPCAddress, req.Status, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -257,15 +281,17 @@ func HandlerGetPlans(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetPlan(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetPlan(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryPlan(req.Query.RPCAddress, req.URI.ID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -275,15 +301,17 @@ func HandlerGetPlan(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetProviders(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetProviders(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryProviders(req.Query.RPCAddress, req.Status, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -293,15 +321,17 @@ func HandlerGetProviders(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetProvider(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetProvider(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryProvider(req.Query.RPCAddress, req.ProvAddress)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -311,15 +341,17 @@ func HandlerGetProvider(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetNodesForPlan(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetNodesForPlan(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryNodesForPlan(req.Query.RPCAddress, req.URI.ID, req.Status, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -329,15 +361,17 @@ func HandlerGetNodesForPlan(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetPlansForProvider(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetPlansForProvider(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryPlansForProvider(req.Query.RPCAddress, req.ProvAddress, req.Status, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -347,15 +381,17 @@ func HandlerGetPlansForProvider(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetSessions(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetSessions(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QuerySessions(req.Query.RPCAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -365,15 +401,17 @@ func HandlerGetSessions(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetSession(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetSession(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QuerySession(req.Query.RPCAddress, req.URI.ID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -383,15 +421,17 @@ func HandlerGetSession(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetSubscriptions(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetSubscriptions(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QuerySubscriptions(req.Query.RPCAddress, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -401,15 +441,17 @@ func HandlerGetSubscriptions(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetSubscription(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetSubscription(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QuerySubscription(req.Query.RPCAddress, req.URI.ID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -419,15 +461,17 @@ func HandlerGetSubscription(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetAllocationsForSubscription(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetAllocationsForSubscription(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryAllocations(req.Query.RPCAddress, req.URI.ID, req.Pagination)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
@@ -437,18 +481,102 @@ func HandlerGetAllocationsForSubscription(ctx context.Context) gin.HandlerFunc {
 
 func HandlerGetAllocationForSubscription(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestGetAllocationForSubscription(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
 		result, err := ctx.QueryAllocation(req.Query.RPCAddress, req.URI.ID, req.AccAddress)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, types.NewResponseResult(result))
 	}
 }
+
+func HandlerGetTx(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestGetTx(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		result, err := ctx.QueryTx(req.Query.RPCAddress, req.URI.Hash)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		if result == nil {
+			found, err := ctx.QueryUnconfirmedTx(req.Query.RPCAddress, req.URI.Hash)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+
+			item := &responses.ResponseTx{
+				Hash:   strings.ToUpper(req.URI.Hash),
+				Status: responses.TxStatusNotFound,
+			}
+			if found {
+				item.Status = responses.TxStatusPending
+			}
+
+			c.JSON(http.StatusOK, types.NewResponseResult(item))
+			return
+		}
+
+		item, err := responses.NewResponseTx(ctx.TxConfig, result)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(item))
+	}
+}
+
+func HandlerGetTxs(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestGetTxs(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		result, err := ctx.QueryTxSearch(req.Query.RPCAddress, req.Events, req.Query.Page, req.Query.Limit, req.OrderBy)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		items := &responses.ResponseTxSearch{
+			Total: result.TotalCount,
+			Page:  req.Query.Page,
+			Limit: req.Query.Limit,
+			Txs:   []*responses.ResponseTx{},
+		}
+
+		for _, v := range result.Txs {
+			item, err := responses.NewResponseTx(ctx.TxConfig, v)
+			if err != nil {
+				abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
+				return
+			}
+
+			items.Txs = append(items.Txs, item)
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(items))
+	}
+}
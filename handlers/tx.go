@@ -1,41 +1,288 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
+	cryptohd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/authz"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/cosmos/cosmos-sdk/x/feegrant"
 	"github.com/gin-gonic/gin"
+	hubtypes "github.com/sentinel-official/hub/types"
 	nodetypes "github.com/sentinel-official/hub/x/node/types"
 	plantypes "github.com/sentinel-official/hub/x/plan/types"
 	sessiontypes "github.com/sentinel-official/hub/x/session/types"
 	subscriptiontypes "github.com/sentinel-official/hub/x/subscription/types"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
 
 	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/crypto/ethsecp256k1"
 	"github.com/solarlabsteam/sentinel-api-backend/requests"
+	"github.com/solarlabsteam/sentinel-api-backend/responses"
 	"github.com/solarlabsteam/sentinel-api-backend/types"
 	"github.com/solarlabsteam/sentinel-api-backend/utils"
 )
 
+// broadcastTx signs and broadcasts the messages, and waits for the transaction to be
+// included in a block. Both the result and the error report the attempts made.
+func broadcastTx(
+	ctx context.Context, progress func(stage string), kr keyring.Keyring, from string, query *requests.TxQuery,
+	body *requests.TxBody, feeGranter sdk.AccAddress, messages ...sdk.Msg,
+) (*responses.ResponseTxResult, error) {
+	progress(jobStageBroadcasting)
+	txResp, attempts, err := ctx.Tx(
+		kr, from, query.Gas, query.GasAdjustment, query.GasPrices,
+		body.Fees, feeGranter, body.Memo, body.SignMode, query.ChainID, query.RPCAddress,
+		body.TimeoutHeight, query.SimulateAndExecute, query.BroadcastMode, messages...,
+	)
+	if err == nil {
+		progress(jobStageWaiting)
+
+		var txRes *coretypes.ResultTx
+		if txRes, err = waitForTx(ctx, query.RPCAddress, query.MaxQueryTries, txResp); err == nil {
+			result := newResponseTxResult(ctx, txRes, attempts)
+			publishTxOutcome(ctx, body.CallbackURL, txResp, result, nil)
+
+			return result, nil
+		}
+	}
+
+	v := types.AsError(err)
+	v.Attempts = attempts
+	publishTxOutcome(ctx, body.CallbackURL, txResp, nil, v)
+
+	return nil, v
+}
+
+// newResponseTxResult decodes the result of the transaction. The transaction is committed,
+// so the parts of the result which cannot be decoded are only logged, as failing the
+// request would have the caller broadcast the transaction again.
+func newResponseTxResult(ctx context.Context, txRes *coretypes.ResultTx, attempts int64) *responses.ResponseTxResult {
+	result, err := responses.NewResponseTxResult(ctx.Codec, ctx.TxConfig, txRes, attempts)
+	if err != nil {
+		log.Printf("failed to decode the result of transaction %s: %s", result.Hash, err)
+	}
+
+	return result
+}
+
+// waitForTx waits for the broadcast transaction to be included in a block. The returned
+// errors are entries of the error catalogue.
+func waitForTx(ctx context.Context, rpcAddress string, maxQueryTries int64, txResp *sdk.TxResponse) (*coretypes.ResultTx, error) {
+	if txResp.Code != abcitypes.CodeTypeOK {
+		// The transaction is only executed in the block broadcast mode, otherwise
+		// the code is the one of CheckTx.
+		code := types.ErrorCodeTxRejected
+		if txResp.Height > 0 {
+			code = types.ErrorCodeTxFailed
+		}
+
+		return nil, types.NewErrorFromABCI(code, txResp.Codespace, txResp.Code, txResp.RawLog)
+	}
+
+	txRes, err := ctx.WaitForTx(rpcAddress, txResp.TxHash, maxQueryTries)
+	if err != nil {
+		return nil, err
+	}
+	if txRes == nil {
+		err := fmt.Errorf("transaction %s is not included in a block after %d tries", txResp.TxHash, maxQueryTries)
+		return nil, types.NewError(types.ErrorCodeTimeout, err)
+	}
+	if !txRes.TxResult.IsOK() {
+		return nil, types.NewErrorFromABCI(
+			types.ErrorCodeTxFailed, txRes.TxResult.Codespace, txRes.TxResult.Code, txRes.TxResult.Log,
+		)
+	}
+
+	return txRes, nil
+}
+
+// newSigner returns the keyring holding the key of the signer and its address. The key
+// is either a key of the keystore, derived from the mnemonic or imported from the
+// private key, all of which resolve into an in-memory keyring. In the generate only
+// mode, and optionally in the simulate only mode, there is no key and the address is
+// the one given by the caller.
+func newSigner(ctx context.Context, query *requests.TxQuery, body *requests.TxBody) (keyring.Keyring, sdk.AccAddress, error) {
+	if query.GenerateOnly && body.From == "" {
+		err := errors.New("from cannot be empty in the generate only mode")
+		return nil, nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
+	if (query.GenerateOnly || query.SimulateOnly) && body.From != "" {
+		accAddr, err := sdk.AccAddressFromBech32(body.From)
+		if err != nil {
+			return nil, nil, types.NewError(types.ErrorCodeBadInput, err)
+		}
+
+		return nil, accAddr, nil
+	}
+
+	var (
+		kr  keyring.Keyring
+		key keyring.Info
+		err error
+	)
+
+	if body.KeyName != "" {
+		if body.KeyPassphrase == "" && !query.Admin {
+			err := errors.New("key_passphrase cannot be empty without the admin token")
+			return nil, nil, types.NewError(types.ErrorCodeUnauthorized, err)
+		}
+
+		kr, key, err = ctx.Keystore().Keyring(body.KeyName, body.KeyPassphrase)
+		if err != nil {
+			return nil, nil, newKeystoreError(err)
+		}
+	} else {
+		if body.Mnemonic == "" && body.PrivateKey == "" {
+			err := errors.New("mnemonic, private_key or key_name cannot be empty")
+			return nil, nil, types.NewError(types.ErrorCodeBadInput, err)
+		}
+		if body.Mnemonic != "" && body.PrivateKey != "" {
+			err := errors.New("mnemonic and private_key cannot be both set")
+			return nil, nil, types.NewError(types.ErrorCodeBadInput, err)
+		}
+
+		kr, key, err = utils.NewKey(
+			&utils.KeySource{
+				Algo:                 query.Algo,
+				Mnemonic:             body.Mnemonic,
+				BIP39Password:        body.BIP39Password,
+				CoinType:             query.CoinType,
+				Account:              query.Account,
+				Index:                query.Index,
+				PrivateKey:           body.PrivateKey,
+				PrivateKeyPassphrase: body.PrivateKeyPassphrase,
+			},
+		)
+		if err != nil {
+			return nil, nil, types.NewError(types.ErrorCodeBadInput, err)
+		}
+	}
+
+	// The hub only accepts the signatures of secp256k1 keys, whereas an armored private
+	// key may hold an eth_secp256k1 one. The keys of EVM wallets sign as secp256k1 keys
+	// derived at the coin type 60.
+	if key.GetAlgo() != cryptohd.Secp256k1Type {
+		err := fmt.Errorf("%s keys cannot sign transactions, use secp256k1 with coin_type %d instead", key.GetAlgo(), ethsecp256k1.CoinType)
+		return nil, nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	return kr, key.GetAddress(), nil
+}
+
+// validateMessages runs the stateless checks of the messages, including the ones executed
+// on behalf of an authz granter, so that invalid messages are rejected as bad input
+// before the chain is queried.
+func validateMessages(messages []sdk.Msg) error {
+	for i, message := range messages {
+		if err := message.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid message %d: %w", i, err)
+		}
+
+		if v, ok := message.(*authz.MsgExec); ok {
+			msgs, err := v.GetMessages()
+			if err != nil {
+				return fmt.Errorf("invalid message %d: %w", i, err)
+			}
+			if err := validateMessages(msgs); err != nil {
+				return fmt.Errorf("invalid message %d: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// processTx returns the unsigned transaction in the generate only mode and the estimated
+// gas and fees in the simulate only mode, otherwise it signs and broadcasts the messages
+// and waits for the transaction to be included in a block. In the async mode the broadcast
+// runs as a job, which is returned instead of the result. The messages are validated first.
+func processTx(
+	ctx context.Context, kr keyring.Keyring, accAddr sdk.AccAddress, query *requests.TxQuery, body *requests.TxBody,
+	feeGranter sdk.AccAddress, messages ...sdk.Msg,
+) (interface{}, error) {
+	if err := validateMessages(messages); err != nil {
+		return nil, types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	return processValidTx(ctx, kr, accAddr, query, body, feeGranter, messages...)
+}
+
+// processValidTx is processTx for the messages which were validated already.
+func processValidTx(
+	ctx context.Context, kr keyring.Keyring, accAddr sdk.AccAddress, query *requests.TxQuery, body *requests.TxBody,
+	feeGranter sdk.AccAddress, messages ...sdk.Msg,
+) (interface{}, error) {
+	if query.SimulateOnly {
+		gasUsed, tx, err := ctx.SimulateTx(
+			kr, accAddr, query.GasAdjustment, query.GasPrices, body.Fees, feeGranter, body.Memo,
+			query.ChainID, query.RPCAddress, body.TimeoutHeight, messages...,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return &responses.ResponseSimulateTx{
+			GasUsed:  gasUsed,
+			GasLimit: tx.GetGas(),
+			Fees:     tx.GetFee(),
+		}, nil
+	}
+	if query.GenerateOnly {
+		tx, signerData, err := ctx.GenerateTx(
+			accAddr, query.Gas, query.GasAdjustment, query.GasPrices, body.Fees, feeGranter, body.Memo,
+			query.ChainID, query.RPCAddress, body.TimeoutHeight, query.SimulateAndExecute, messages...,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := responses.NewResponseUnsignedTx(ctx.TxConfig, tx, signerData)
+		if err != nil {
+			return nil, types.NewError(types.ErrorCodeInternal, err)
+		}
+
+		return result, nil
+	}
+
+	key, err := kr.KeyByAddress(accAddr)
+	if err != nil {
+		return nil, types.NewError(types.ErrorCodeInternal, err)
+	}
+
+	if query.Async {
+		return submitJob(ctx, func(ctx context.Context, progress func(stage string)) (interface{}, error) {
+			return broadcastTx(ctx, progress, kr, key.GetName(), query, body, feeGranter, messages...)
+		})
+	}
+
+	return broadcastTx(ctx, noProgress, kr, key.GetName(), query, body, feeGranter, messages...)
+}
+
 func HandlerTxAuthzGrant(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxAuthzGrant(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -50,7 +297,7 @@ func HandlerTxAuthzGrant(ctx context.Context) gin.HandlerFunc {
 		for i := 0; i < len(req.AccAddresses); i++ {
 			message, err := authz.NewMsgGrant(fromAddr, req.AccAddresses[i], genericAuthz, req.Body.Expiration)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.NewResponseError(3, err))
+				abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 				return
 			}
 
@@ -58,55 +305,37 @@ func HandlerTxAuthzGrant(ctx context.Context) gin.HandlerFunc {
 		}
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
-			return
-		}
-
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxFeegrantGrantAllowance(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxFeegrantGrantAllowance(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -136,7 +365,7 @@ func HandlerTxFeegrantGrantAllowance(ctx context.Context) gin.HandlerFunc {
 
 			if expiration != nil && expiration.Sub(periodicAllowance.PeriodReset) < 0 {
 				err := fmt.Errorf("period_reset cannot be grater than expiration")
-				c.JSON(http.StatusBadRequest, types.NewResponseError(3, err))
+				abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 				return
 			}
 
@@ -145,7 +374,7 @@ func HandlerTxFeegrantGrantAllowance(ctx context.Context) gin.HandlerFunc {
 		if req.Body.AllowedMsgs != nil {
 			allowedMsgAllowance, err := feegrant.NewAllowedMsgAllowance(grant, req.Body.AllowedMsgs)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.NewResponseError(3, err))
+				abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 				return
 			}
 
@@ -155,7 +384,7 @@ func HandlerTxFeegrantGrantAllowance(ctx context.Context) gin.HandlerFunc {
 		for i := 0; i < len(req.AccAddresses); i++ {
 			message, err := feegrant.NewMsgGrantAllowance(grant, fromAddr, req.AccAddresses[i])
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.NewResponseError(3, err))
+				abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 				return
 			}
 
@@ -163,55 +392,37 @@ func HandlerTxFeegrantGrantAllowance(ctx context.Context) gin.HandlerFunc {
 		}
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxBankSend(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxBankSend(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -222,55 +433,37 @@ func HandlerTxBankSend(ctx context.Context) gin.HandlerFunc {
 		}
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
-			return
-		}
-
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxPlanCreate(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxPlanCreate(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -279,55 +472,37 @@ func HandlerTxPlanCreate(ctx context.Context) gin.HandlerFunc {
 		messages = append(messages, plantypes.NewMsgCreateRequest(fromAddr.Bytes(), req.Body.Duration, req.Body.Gigabytes, req.Prices))
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxPlanUpdateStatus(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxPlanUpdateStatus(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -336,55 +511,37 @@ func HandlerTxPlanUpdateStatus(ctx context.Context) gin.HandlerFunc {
 		messages = append(messages, plantypes.NewMsgUpdateStatusRequest(fromAddr.Bytes(), req.URI.ID, req.Status))
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxPlanLinkNode(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxPlanLinkNode(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -395,55 +552,37 @@ func HandlerTxPlanLinkNode(ctx context.Context) gin.HandlerFunc {
 		}
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxPlanUnlinkNode(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxPlanUnlinkNode(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -452,169 +591,259 @@ func HandlerTxPlanUnlinkNode(ctx context.Context) gin.HandlerFunc {
 		messages = append(messages, plantypes.NewMsgUnlinkNodeRequest(fromAddr.Bytes(), req.URI.ID, req.NodeAddress))
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
+	}
+}
+
+// checkNodeAddress checks that the node is the one of the account signing its messages,
+// as the node address and the account address of an operator share the same bytes.
+func checkNodeAddress(nodeAddr hubtypes.NodeAddress, accAddr sdk.AccAddress) error {
+	if !nodeAddr.Equals(hubtypes.NodeAddress(accAddr.Bytes())) {
+		err := fmt.Errorf("node %s does not belong to the account %s", nodeAddr, accAddr)
+		return types.NewError(types.ErrorCodeBadInput, err)
+	}
+
+	return nil
+}
+
+func HandlerTxNodeRegister(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxNodeRegister(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		fromAddr := accAddr
+		if !req.AuthzGranter.Empty() {
+			fromAddr = req.AuthzGranter
+		}
+
+		message := nodetypes.NewMsgRegisterRequest(fromAddr, req.GigabytePrices, req.HourlyPrices, req.Body.RemoteURL)
+
+		var messages []sdk.Msg
+		messages = append(messages, message)
+
+		if !req.AuthzGranter.Empty() {
+			execMsg := authz.NewMsgExec(accAddr, messages)
+			messages = []sdk.Msg{&execMsg}
+		}
+
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
-func HandlerTxNodeSubscribe(ctx context.Context) gin.HandlerFunc {
+func HandlerTxNodeUpdateDetails(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		req, err := requests.NewRequestTxNodeSubscribe(c)
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxNodeUpdateDetails(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
 
+		if err := checkNodeAddress(req.NodeAddress, fromAddr); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		message := nodetypes.NewMsgUpdateDetailsRequest(req.NodeAddress, req.GigabytePrices, req.HourlyPrices, req.Body.RemoteURL)
+
 		var messages []sdk.Msg
-		messages = append(messages, nodetypes.NewMsgSubscribeRequest(fromAddr, req.NodeAddress, req.Body.Gigabytes, req.Body.Hours, req.Body.Denom))
+		messages = append(messages, message)
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
+	}
+}
+
+func HandlerTxNodeUpdateStatus(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxNodeUpdateStatus(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		fromAddr := accAddr
+		if !req.AuthzGranter.Empty() {
+			fromAddr = req.AuthzGranter
+		}
+
+		if err := checkNodeAddress(req.NodeAddress, fromAddr); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		message := nodetypes.NewMsgUpdateStatusRequest(req.NodeAddress, req.Status)
+
+		var messages []sdk.Msg
+		messages = append(messages, message)
+
+		if !req.AuthzGranter.Empty() {
+			execMsg := authz.NewMsgExec(accAddr, messages)
+			messages = []sdk.Msg{&execMsg}
+		}
+
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
-func HandlerTxPlanSubscribe(ctx context.Context) gin.HandlerFunc {
+func HandlerTxNodeSubscribe(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		req, err := requests.NewRequestTxPlanSubscribe(c)
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxNodeSubscribe(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
 
 		var messages []sdk.Msg
-		messages = append(messages, plantypes.NewMsgSubscribeRequest(fromAddr, req.URI.ID, req.Body.Denom))
+		messages = append(messages, nodetypes.NewMsgSubscribeRequest(fromAddr, req.NodeAddress, req.Body.Gigabytes, req.Body.Hours, req.Body.Denom))
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
+	}
+}
+
+func HandlerTxPlanSubscribe(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxPlanSubscribe(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		fromAddr := accAddr
+		if !req.AuthzGranter.Empty() {
+			fromAddr = req.AuthzGranter
+		}
+
+		var messages []sdk.Msg
+		messages = append(messages, plantypes.NewMsgSubscribeRequest(fromAddr, req.URI.ID, req.Body.Denom))
+
+		if !req.AuthzGranter.Empty() {
+			execMsg := authz.NewMsgExec(accAddr, messages)
+			messages = []sdk.Msg{&execMsg}
+		}
+
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxSubscriptionAllocate(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxSubscriptionAllocate(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -625,55 +854,37 @@ func HandlerTxSubscriptionAllocate(ctx context.Context) gin.HandlerFunc {
 		}
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxSessionStart(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxSessionStart(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -682,55 +893,110 @@ func HandlerTxSessionStart(ctx context.Context) gin.HandlerFunc {
 		messages = append(messages, sessiontypes.NewMsgStartRequest(fromAddr, req.URI.ID, req.NodeAddress))
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
+	}
+}
+
+// HandlerTxSessionEnd ends the given sessions with the same rating, and all the active
+// sessions of the account when requested.
+func HandlerTxSessionEnd(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxSessionEnd(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, err)
 			return
 		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		fromAddr := accAddr
+		if !req.AuthzGranter.Empty() {
+			fromAddr = req.AuthzGranter
+		}
+
+		ids := req.IDs
+		if req.Body.All {
+			items, err := ctx.QueryActiveSessions(req.Query.RPCAddress, fromAddr)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+
+			for _, item := range items {
+				if !containsID(ids, item.ID) {
+					ids = append(ids, item.ID)
+				}
+			}
+		}
+		if len(ids) == 0 {
+			err := fmt.Errorf("account %s has no active sessions", fromAddr)
+			abortWithError(c, types.NewError(types.ErrorCodeNotFound, err))
 			return
 		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		var messages []sdk.Msg
+		for _, id := range ids {
+			messages = append(messages, sessiontypes.NewMsgEndRequest(fromAddr, id, req.Body.Rating))
+		}
+
+		if !req.AuthzGranter.Empty() {
+			execMsg := authz.NewMsgExec(accAddr, messages)
+			messages = []sdk.Msg{&execMsg}
+		}
+
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
+	}
+}
+
+func containsID(ids []uint64, id uint64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
 	}
+
+	return false
 }
 
 func HandlerTxSubscribe(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxSubscribe(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -754,55 +1020,37 @@ func HandlerTxSubscribe(ctx context.Context) gin.HandlerFunc {
 		}
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
-			return
-		}
-
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
-			return
-		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
 
 func HandlerTxSubscriptionCancel(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
 		req, err := requests.NewRequestTxSubscriptionCancel(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
 
-		kr, key, err := utils.NewInMemoryKey(req.Body.Mnemonic, req.Query.CoinType, req.Query.Account, req.Query.Index, req.Body.BIP39Password)
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(2, err))
+			abortWithError(c, err)
 			return
 		}
 
-		fromAddr := key.GetAddress()
+		fromAddr := accAddr
 		if !req.AuthzGranter.Empty() {
 			fromAddr = req.AuthzGranter
 		}
@@ -813,36 +1061,120 @@ func HandlerTxSubscriptionCancel(ctx context.Context) gin.HandlerFunc {
 		}
 
 		if !req.AuthzGranter.Empty() {
-			execMsg := authz.NewMsgExec(key.GetAddress(), messages)
+			execMsg := authz.NewMsgExec(accAddr, messages)
 			messages = []sdk.Msg{&execMsg}
 		}
 
-		txResp, err := ctx.Tx(
-			kr, key.GetName(), req.Query.Gas, req.Query.GasAdjustment, req.Query.GasPrices,
-			req.Body.Fees, req.FeeGranter, req.Body.Memo, req.Body.SignMode, req.Query.ChainID, req.Query.RPCAddress,
-			req.Body.TimeoutHeight, req.Query.SimulateAndExecute, req.Query.BroadcastMode, messages...,
-		)
+		result, err := processTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(3, err))
+			abortWithError(c, err)
 			return
 		}
 
-		txRes, err := ctx.QueryTxWithRetry(req.Query.RPCAddress, txResp.TxHash, req.Query.MaxQueryTries)
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
+	}
+}
+
+func HandlerTxBroadcast(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxBroadcast(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
 			return
 		}
-		if txRes == nil {
-			err := fmt.Errorf("query result is nil for the transaction %s", txResp.TxHash)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		txBytes := req.TxBytes
+		if len(req.Body.Tx) != 0 {
+			txBytes, err = ctx.EncodeTxJSON(req.Query.RPCAddress, req.Body.Tx)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+		}
+
+		txResp, err := ctx.BroadcastTxBytes(req.Query.RPCAddress, req.Query.BroadcastMode, txBytes)
+		if err != nil {
+			publishTxOutcome(ctx, req.Body.CallbackURL, nil, nil, err)
+			abortWithError(c, err)
 			return
 		}
-		if !txRes.TxResult.IsOK() {
-			err := fmt.Errorf("transaction %s failed with the code %d", txResp.TxHash, txRes.TxResult.Code)
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+
+		txRes, err := waitForTx(ctx, req.Query.RPCAddress, req.Query.MaxQueryTries, txResp)
+		if err != nil {
+			publishTxOutcome(ctx, req.Body.CallbackURL, txResp, nil, err)
+			abortWithError(c, err)
+			return
+		}
+
+		result := newResponseTxResult(ctx, txRes, 1)
+		publishTxOutcome(ctx, req.Body.CallbackURL, txResp, result, nil)
+
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
+
+func HandlerTxMessages(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctx.WithContext(c.Request.Context())
+
+		req, err := requests.NewRequestTxMessages(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		kr, accAddr, err := newSigner(ctx, &req.Query, &req.Body.TxBody)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		fromAddr := accAddr
+		if !req.AuthzGranter.Empty() {
+			fromAddr = req.AuthzGranter
+		}
+
+		var messages []sdk.Msg
+		for i, buf := range req.Body.Messages {
+			var message sdk.Msg
+			if err := ctx.Codec.UnmarshalInterfaceJSON(buf, &message); err != nil {
+				err := fmt.Errorf("invalid message %d: %w", i, err)
+				abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+				return
+			}
+
+			messages = append(messages, message)
+		}
+
+		// The signers of the messages are only known once they are validated.
+		if err := validateMessages(messages); err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		for i, message := range messages {
+			for _, signer := range message.GetSigners() {
+				if !signer.Equals(fromAddr) {
+					err := fmt.Errorf("message %d has signer %s, expected %s", i, signer, fromAddr)
+					abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+					return
+				}
+			}
+		}
+
+		if !req.AuthzGranter.Empty() {
+			execMsg := authz.NewMsgExec(accAddr, messages)
+			messages = []sdk.Msg{&execMsg}
+		}
+
+		result, err := processValidTx(ctx, kr, accAddr, &req.Query, &req.Body.TxBody, req.FeeGranter, messages...)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, types.NewResponseResult(txRes))
+		c.JSON(resultStatusCode(result), types.NewResponseResult(result))
 	}
 }
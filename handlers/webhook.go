@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/requests"
+	"github.com/solarlabsteam/sentinel-api-backend/responses"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+	"github.com/solarlabsteam/sentinel-api-backend/webhooks"
+)
+
+// publishTxOutcome notifies the callback URL and the subscriptions of the confirmation
+// or the failure of a broadcast transaction.
+func publishTxOutcome(
+	ctx context.Context, callbackURL string, txResp *sdk.TxResponse, result *responses.ResponseTxResult, err error,
+) {
+	if err == nil {
+		ctx.Webhooks().Publish(webhooks.EventTxConfirmed, callbackURL, result)
+		return
+	}
+
+	data := &webhooks.TxFailed{
+		Error: types.AsError(err),
+	}
+	if txResp != nil {
+		data.Hash = txResp.TxHash
+	}
+
+	ctx.Webhooks().Publish(webhooks.EventTxFailed, callbackURL, data)
+}
+
+func HandlerGetWebhooks(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result := ctx.Webhooks().Registry().List()
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
+
+func HandlerAddWebhook(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestAddWebhook(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		result, err := ctx.Webhooks().Registry().Add(req.Body.URL, req.Body.Events)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeInternal, err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, types.NewResponseResult(result))
+	}
+}
+
+func HandlerDeleteWebhook(ctx context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := requests.NewRequestDeleteWebhook(c)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		if !ctx.Webhooks().Registry().Delete(req.URI.ID) {
+			err := fmt.Errorf("webhook %s does not exist", req.URI.ID)
+			abortWithError(c, types.NewError(types.ErrorCodeNotFound, err))
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(nil))
+	}
+}
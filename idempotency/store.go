@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTTL = 24 * time.Hour
+)
+
+var (
+	ErrKeyReused = errors.New("idempotency key was used for a different request")
+)
+
+// Outcome is the response to the first request made with a key.
+type Outcome struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type entry struct {
+	fingerprint string
+	done        chan struct{}
+	outcome     *Outcome
+	expiresAt   time.Time
+}
+
+// Store keeps the outcomes of the requests by their idempotency key for the TTL.
+// A request is fingerprinted, so that a key cannot be reused for another request.
+type Store struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	ttl     time.Duration
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		entries: make(map[string]*entry),
+		ttl:     ttl,
+	}
+}
+
+func NewDefaultStore() *Store {
+	return NewStore(DefaultTTL)
+}
+
+// Begin returns the outcome of the request made with the key, waiting for it while the
+// request is in flight. When there is no request with the key, it returns a nil outcome
+// and the caller must call Complete or Abandon once done with the request.
+func (s *Store) Begin(ctx context.Context, key, fingerprint string) (*Outcome, error) {
+	for {
+		s.mu.Lock()
+		s.evict(time.Now())
+
+		item, ok := s.entries[key]
+		if !ok {
+			s.entries[key] = &entry{
+				fingerprint: fingerprint,
+				done:        make(chan struct{}),
+			}
+			s.mu.Unlock()
+
+			return nil, nil
+		}
+		s.mu.Unlock()
+
+		if item.fingerprint != fingerprint {
+			return nil, ErrKeyReused
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-item.done:
+		}
+
+		// The outcome is nil when the request was abandoned, in which case the key
+		// is free again.
+		if item.outcome != nil {
+			return item.outcome, nil
+		}
+	}
+}
+
+// Complete stores the outcome of the request made with the key and releases the
+// requests waiting for it.
+func (s *Store) Complete(key string, v *Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.entries[key]
+	item.outcome = v
+	item.expiresAt = time.Now().Add(s.ttl)
+	close(item.done)
+}
+
+// Abandon frees the key of a request which has no outcome, for instance because its
+// handler panicked.
+func (s *Store) Abandon(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.entries[key]
+	delete(s.entries, key)
+	close(item.done)
+}
+
+// evict removes the outcomes which have been kept for longer than the TTL. The requests
+// in flight are kept.
+func (s *Store) evict(now time.Time) {
+	for key, item := range s.entries {
+		if item.outcome != nil && now.After(item.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
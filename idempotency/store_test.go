@@ -0,0 +1,130 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore_Replay(t *testing.T) {
+	s := NewDefaultStore()
+	ctx := context.Background()
+
+	outcome, err := s.Begin(ctx, "key", "fingerprint")
+	if err != nil || outcome != nil {
+		t.Fatalf("Begin() = %v, %v, want nil, nil", outcome, err)
+	}
+
+	want := &Outcome{StatusCode: 200, ContentType: "application/json", Body: []byte(`{}`)}
+	s.Complete("key", want)
+
+	outcome, err = s.Begin(ctx, "key", "fingerprint")
+	if err != nil || outcome != want {
+		t.Fatalf("Begin() = %v, %v, want %v, nil", outcome, err, want)
+	}
+}
+
+func TestStore_KeyReused(t *testing.T) {
+	s := NewDefaultStore()
+	ctx := context.Background()
+
+	if _, err := s.Begin(ctx, "key", "fingerprint"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	// The fingerprint is checked while the first request is in flight, and after.
+	if _, err := s.Begin(ctx, "key", "other"); !errors.Is(err, ErrKeyReused) {
+		t.Fatalf("Begin() error = %v, want %v", err, ErrKeyReused)
+	}
+
+	s.Complete("key", &Outcome{StatusCode: 200})
+
+	if _, err := s.Begin(ctx, "key", "other"); !errors.Is(err, ErrKeyReused) {
+		t.Fatalf("Begin() error = %v, want %v", err, ErrKeyReused)
+	}
+}
+
+func TestStore_Wait(t *testing.T) {
+	s := NewDefaultStore()
+	ctx := context.Background()
+
+	if _, err := s.Begin(ctx, "key", "fingerprint"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	type result struct {
+		outcome *Outcome
+		err     error
+	}
+
+	done := make(chan result)
+	go func() {
+		outcome, err := s.Begin(ctx, "key", "fingerprint")
+		done <- result{outcome, err}
+	}()
+
+	want := &Outcome{StatusCode: 202}
+	s.Complete("key", want)
+
+	if v := <-done; v.err != nil || v.outcome != want {
+		t.Fatalf("Begin() = %v, %v, want %v, nil", v.outcome, v.err, want)
+	}
+
+	if _, err := s.Begin(ctx, "other", "fingerprint"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := s.Begin(ctx, "other", "fingerprint"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Begin() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestStore_Abandon(t *testing.T) {
+	s := NewDefaultStore()
+	ctx := context.Background()
+
+	if _, err := s.Begin(ctx, "key", "fingerprint"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		outcome, err := s.Begin(ctx, "key", "fingerprint")
+		if err == nil && outcome != nil {
+			err = errors.New("outcome of an abandoned request")
+		}
+		done <- err
+	}()
+
+	// The waiting request takes the key over once it is abandoned.
+	s.Abandon("key")
+	if err := <-done; err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	s.Abandon("key")
+	if _, err := s.Begin(ctx, "key", "other"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+}
+
+func TestStore_Evict(t *testing.T) {
+	s := NewStore(time.Millisecond)
+	ctx := context.Background()
+
+	if _, err := s.Begin(ctx, "key", "fingerprint"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	s.Complete("key", &Outcome{StatusCode: 200})
+	time.Sleep(2 * time.Millisecond)
+
+	outcome, err := s.Begin(ctx, "key", "other")
+	if err != nil || outcome != nil {
+		t.Fatalf("Begin() = %v, %v, want nil, nil", outcome, err)
+	}
+}
@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/solarlabsteam/sentinel-api-backend/metrics"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+const (
+	DefaultWorkers   = 8
+	DefaultQueueSize = 256
+	DefaultTimeout   = 5 * time.Minute
+	DefaultTTL       = time.Hour
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+var (
+	ErrQueueFull = errors.New("job queue is full")
+)
+
+// Func is the work of a job. It reports its progress with the names of the stages
+// it goes through, and its result or error becomes the one of the job.
+type Func func(ctx context.Context, progress func(stage string)) (interface{}, error)
+
+type Job struct {
+	ID        string       `json:"id"`
+	Status    string       `json:"status"`
+	Stage     string       `json:"stage,omitempty"`
+	Result    interface{}  `json:"result,omitempty"`
+	Error     *types.Error `json:"error,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+
+	fn Func
+}
+
+// Queue runs the submitted jobs in a pool of workers and keeps the finished ones
+// for the TTL, so that their results can be fetched.
+type Queue struct {
+	mu    sync.Mutex
+	jobs  map[string]*Job
+	queue chan *Job
+
+	workers int
+	timeout time.Duration
+	ttl     time.Duration
+}
+
+func NewQueue(workers, size int, timeout, ttl time.Duration) *Queue {
+	return &Queue{
+		jobs:    make(map[string]*Job),
+		queue:   make(chan *Job, size),
+		workers: workers,
+		timeout: timeout,
+		ttl:     ttl,
+	}
+}
+
+func NewDefaultQueue() *Queue {
+	return NewQueue(DefaultWorkers, DefaultQueueSize, DefaultTimeout, DefaultTTL)
+}
+
+// Start starts the workers, which stop when the context is done.
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-q.queue:
+					q.run(ctx, job)
+				}
+			}
+		}()
+	}
+}
+
+// Submit queues the work and returns a snapshot of its job.
+func (q *Queue) Submit(fn Func) (*Job, error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	q.evict(now)
+
+	job := &Job{
+		ID:        id,
+		Status:    StatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+		fn:        fn,
+	}
+
+	select {
+	case q.queue <- job:
+	default:
+		return nil, ErrQueueFull
+	}
+
+	q.jobs[id] = job
+	metrics.JobsTotal.WithLabelValues(StatusQueued).Inc()
+
+	v := *job
+	return &v, nil
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (q *Queue) Get(id string) (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.evict(time.Now())
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, false
+	}
+
+	v := *job
+	return &v, true
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	q.update(job, func() { job.Status = StatusRunning })
+
+	result, err := q.call(ctx, job)
+
+	status := StatusSucceeded
+	if err != nil {
+		status = StatusFailed
+	}
+
+	q.update(job, func() {
+		job.Status = status
+		if err != nil {
+			job.Error = types.AsError(err)
+			return
+		}
+
+		job.Result = result
+	})
+
+	metrics.JobsTotal.WithLabelValues(status).Inc()
+}
+
+// call runs the work of the job, turning a panic into an internal error so that a
+// failing job does not take the workers, which run outside of any recovery, down.
+func (q *Queue) call(ctx context.Context, job *Job) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, types.NewError(types.ErrorCodeInternal, fmt.Errorf("job panicked: %v", r))
+		}
+	}()
+
+	return job.fn(ctx, func(stage string) {
+		q.update(job, func() { job.Stage = stage })
+	})
+}
+
+func (q *Queue) update(job *Job, fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	fn()
+	job.UpdatedAt = time.Now()
+}
+
+// evict removes the finished jobs which have been kept for longer than the TTL.
+func (q *Queue) evict(now time.Time) {
+	for id, job := range q.jobs {
+		finished := job.Status == StatusSucceeded || job.Status == StatusFailed
+		if finished && now.Sub(job.UpdatedAt) > q.ttl {
+			delete(q.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+func TestQueue_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := NewDefaultQueue()
+	q.Start(ctx)
+
+	tests := []struct {
+		fn      Func
+		status  string
+		result  interface{}
+		stage   string
+		errCode string
+	}{
+		{
+			fn: func(_ context.Context, progress func(stage string)) (interface{}, error) {
+				progress("stage")
+				return "result", nil
+			},
+			status: StatusSucceeded,
+			result: "result",
+			stage:  "stage",
+		},
+		{
+			fn: func(context.Context, func(string)) (interface{}, error) {
+				return nil, types.NewError(types.ErrorCodeTxFailed, errors.New("failed"))
+			},
+			status:  StatusFailed,
+			errCode: types.ErrorCodeTxFailed,
+		},
+		{
+			fn: func(context.Context, func(string)) (interface{}, error) {
+				var m map[string]interface{}
+				return m["key"].(string), nil
+			},
+			status:  StatusFailed,
+			errCode: types.ErrorCodeInternal,
+		},
+	}
+
+	ids := make([]string, len(tests))
+	for i, tt := range tests {
+		job, err := q.Submit(tt.fn)
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		if job.Status != StatusQueued {
+			t.Fatalf("Submit() status = %s, want %s", job.Status, StatusQueued)
+		}
+
+		ids[i] = job.ID
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for i, tt := range tests {
+		job, ok := q.Get(ids[i])
+		for ok && job.Status != StatusSucceeded && job.Status != StatusFailed && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+			job, ok = q.Get(ids[i])
+		}
+		if !ok {
+			t.Fatalf("Get(%s) = false", ids[i])
+		}
+
+		if job.Status != tt.status || job.Result != tt.result || job.Stage != tt.stage {
+			t.Fatalf("job = %s %v %s, want %s %v %s", job.Status, job.Result, job.Stage, tt.status, tt.result, tt.stage)
+		}
+		if (job.Error == nil) != (tt.errCode == "") || (job.Error != nil && job.Error.Code != tt.errCode) {
+			t.Fatalf("job error = %v, want code %q", job.Error, tt.errCode)
+		}
+	}
+}
+
+func TestQueue_Full(t *testing.T) {
+	q := NewQueue(1, 1, DefaultTimeout, DefaultTTL)
+
+	fn := func(context.Context, func(string)) (interface{}, error) {
+		return nil, nil
+	}
+
+	// The workers are not started, so the queue fills up.
+	if _, err := q.Submit(fn); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, err := q.Submit(fn); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit() error = %v, want %v", err, ErrQueueFull)
+	}
+}
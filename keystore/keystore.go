@@ -0,0 +1,401 @@
+// Package keystore keeps named keys on disk, each of them encrypted with its own
+// passphrase, so that the transaction requests can reference a key instead of
+// carrying a mnemonic.
+//
+// A key is stored in the directory of the keystore as <name>.json, which holds the
+// public information of the key and its private key in the ASCII armored format of
+// the SDK, encrypted with the passphrase of the key. A key is either decrypted for a
+// single request with its passphrase, or unlocked once and kept decrypted in memory
+// until it is locked again or the server restarts.
+//
+// After MaxAttempts consecutive failed passphrases a key rejects the passphrases for
+// LockoutDuration, which limits the rate at which they can be guessed.
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cryptohd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"github.com/solarlabsteam/sentinel-api-backend/utils"
+)
+
+const (
+	fileExt = ".json"
+
+	MaxAttempts     = 5
+	LockoutDuration = time.Minute
+)
+
+var (
+	ErrDisabled      = errors.New("keystore is not configured")
+	ErrKeyExists     = errors.New("key already exists")
+	ErrKeyNotFound   = errors.New("key does not exist")
+	ErrKeyLocked     = errors.New("key is locked")
+	ErrInvalidName   = errors.New("key name must be 1 to 64 letters, digits, underscores or dashes")
+	ErrBadPassphrase = errors.New("invalid passphrase")
+	ErrTooManyFails  = errors.New("too many failed passphrases")
+
+	nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,64}$`)
+)
+
+type Key struct {
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	PubKey    string    `json:"pub_key"`
+	Algo      string    `json:"algo"`
+	CreatedAt time.Time `json:"created_at"`
+	Armor     string    `json:"armor"`
+}
+
+// unlockedKey is a decrypted key, held by an in-memory keyring.
+type unlockedKey struct {
+	kr   keyring.Keyring
+	info keyring.Info
+}
+
+// attempts are the consecutive failed passphrases of a key.
+type attempts struct {
+	count int
+	until time.Time
+}
+
+// Keystore is safe for concurrent use.
+type Keystore struct {
+	mu       sync.RWMutex
+	dir      string
+	unlocked map[string]*unlockedKey
+	failures map[string]*attempts
+}
+
+// NewKeystore returns a keystore keeping its keys in the given directory, which is
+// created with the first key. The keystore is disabled when the directory is empty.
+func NewKeystore(dir string) *Keystore {
+	return &Keystore{
+		dir:      dir,
+		unlocked: make(map[string]*unlockedKey),
+		failures: make(map[string]*attempts),
+	}
+}
+
+func (s *Keystore) Enabled() bool {
+	return s.dir != ""
+}
+
+func (s *Keystore) path(name string) string {
+	return filepath.Join(s.dir, name+fileExt)
+}
+
+func (s *Keystore) check(name string) error {
+	if !s.Enabled() {
+		return ErrDisabled
+	}
+	if !nameRegexp.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	return nil
+}
+
+func (s *Keystore) read(name string) (*Key, error) {
+	buf, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var v Key
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// Add stores the key of the algorithm derived from the mnemonic, or from a new mnemonic
+// when it is empty, encrypted with the passphrase. The new mnemonic is returned, if any.
+func (s *Keystore) Add(name, mnemonic, bip39Password, algo string, coinType, account, index uint32, passphrase string) (*Key, string, error) {
+	if err := s.check(name); err != nil {
+		return nil, "", err
+	}
+
+	signingAlgo, err := utils.NewSigningAlgo(algo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		kr   = utils.NewInMemoryKeyring()
+		path = cryptohd.CreateHDPath(coinType, account, index).String()
+		info keyring.Info
+	)
+
+	if mnemonic == "" {
+		info, mnemonic, err = kr.NewMnemonic(name, keyring.English, path, bip39Password, signingAlgo)
+	} else {
+		info, err = kr.NewAccount(name, mnemonic, bip39Password, path, signingAlgo)
+		mnemonic = ""
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	armor, err := kr.ExportPrivKeyArmor(name, passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := s.add(info, armor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, mnemonic, nil
+}
+
+func (s *Keystore) add(info keyring.Info, armor string) (*Key, error) {
+	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, info.GetPubKey())
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		Name:      info.GetName(),
+		Address:   info.GetAddress().String(),
+		PubKey:    pubKey,
+		Algo:      string(info.GetAlgo()),
+		CreatedAt: time.Now().UTC(),
+		Armor:     armor,
+	}
+
+	buf, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(s.path(key.Name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyExists, key.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Write(buf); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return key, file.Close()
+}
+
+// List returns the keys sorted by name.
+func (s *Keystore) List() ([]*Key, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Key{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Key, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), fileExt)
+		if entry.IsDir() || name == entry.Name() || !nameRegexp.MatchString(name) {
+			continue
+		}
+
+		v, err := s.read(name)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, v)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items, nil
+}
+
+// IsUnlocked returns whether the key is kept decrypted in memory.
+func (s *Keystore) IsUnlocked(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.unlocked[name]
+	return ok
+}
+
+func (s *Keystore) Delete(name string) error {
+	if err := s.check(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	delete(s.unlocked, name)
+	delete(s.failures, name)
+	return nil
+}
+
+// checkAttempts rejects the passphrases of a key during the lockout which follows its
+// failed passphrases.
+func (s *Keystore) checkAttempts(name string, now time.Time) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if v, ok := s.failures[name]; ok && now.Before(v.until) {
+		return fmt.Errorf("%w: %s; retry after %s", ErrTooManyFails, name, v.until.Sub(now).Round(time.Second))
+	}
+
+	return nil
+}
+
+// fail records a failed passphrase of the key, and locks it out once it has failed
+// MaxAttempts times in a row. Each failed passphrase after that renews the lockout.
+func (s *Keystore) fail(name string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.failures[name]
+	if !ok {
+		v = &attempts{}
+		s.failures[name] = v
+	}
+
+	v.count++
+	if v.count >= MaxAttempts {
+		v.until = now.Add(LockoutDuration)
+	}
+}
+
+// decrypt returns an in-memory keyring holding the decrypted key.
+func (s *Keystore) decrypt(name, passphrase string) (*unlockedKey, error) {
+	now := time.Now()
+	if err := s.checkAttempts(name, now); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	v, err := s.read(name)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	kr, info, err := utils.NewInMemoryKeyFromPrivKey(v.Armor, passphrase, "")
+	if err != nil {
+		// The SDK only maps the decryption error to ErrWrongPassword when it is
+		// capitalized, which it is not in the Tendermint version in use.
+		if errors.Is(err, sdkerrors.ErrWrongPassword) || strings.Contains(err.Error(), "ciphertext decryption failed") {
+			s.fail(name, now)
+			return nil, ErrBadPassphrase
+		}
+
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.failures, name)
+	s.mu.Unlock()
+
+	return &unlockedKey{kr: kr, info: info}, nil
+}
+
+// Unlock decrypts the key and keeps it in memory, so that it can be used without
+// its passphrase.
+func (s *Keystore) Unlock(name, passphrase string) error {
+	if err := s.check(name); err != nil {
+		return err
+	}
+
+	v, err := s.decrypt(name, passphrase)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unlocked[name] = v
+	return nil
+}
+
+func (s *Keystore) Lock(name string) error {
+	if err := s.check(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.unlocked, name)
+	return nil
+}
+
+// Keyring returns a keyring holding the key, which is decrypted with the passphrase
+// when it is not empty and must have been unlocked otherwise.
+func (s *Keystore) Keyring(name, passphrase string) (keyring.Keyring, keyring.Info, error) {
+	if err := s.check(name); err != nil {
+		return nil, nil, err
+	}
+
+	if passphrase != "" {
+		v, err := s.decrypt(name, passphrase)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return v.kr, v.info, nil
+	}
+
+	s.mu.RLock()
+	v, ok := s.unlocked[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrKeyLocked, name)
+	}
+
+	return v.kr, v.info, nil
+}
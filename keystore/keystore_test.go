@@ -0,0 +1,114 @@
+package keystore
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	testMnemonic   = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	testAddress    = "28FF5C6D57D8CFD492B6FB42614536ED648E01FD"
+	testPassphrase = "passphrase"
+)
+
+func equalsTestAddress(addr sdk.AccAddress) bool {
+	v, err := sdk.AccAddressFromHex(testAddress)
+	return err == nil && v.Equals(addr)
+}
+
+func newTestKeystore(t *testing.T) *Keystore {
+	t.Helper()
+
+	s := NewKeystore(t.TempDir())
+	key, mnemonic, err := s.Add("test", testMnemonic, "", "", 118, 0, 0, testPassphrase)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if addr, err := sdk.AccAddressFromBech32(key.Address); err != nil || !equalsTestAddress(addr) {
+		t.Fatalf("Add() address = %s, want %s", key.Address, testAddress)
+	}
+	if mnemonic != "" {
+		t.Fatalf("Add() returned the given mnemonic")
+	}
+
+	return s
+}
+
+func TestKeystore_Keyring(t *testing.T) {
+	s := newTestKeystore(t)
+
+	kr, info, err := s.Keyring("test", testPassphrase)
+	if err != nil {
+		t.Fatalf("Keyring() error = %v", err)
+	}
+	if !equalsTestAddress(info.GetAddress()) {
+		t.Fatalf("Keyring() address = %s, want %s", info.GetAddress(), testAddress)
+	}
+
+	msg := []byte("message")
+	sig, pubKey, err := kr.Sign(info.GetName(), msg)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if !pubKey.VerifySignature(msg, sig) {
+		t.Fatalf("VerifySignature() = false")
+	}
+
+	if _, _, err := s.Keyring("test", "invalid"); !errors.Is(err, ErrBadPassphrase) {
+		t.Fatalf("Keyring() error = %v, want %v", err, ErrBadPassphrase)
+	}
+	if _, _, err := s.Keyring("missing", testPassphrase); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Keyring() error = %v, want %v", err, ErrKeyNotFound)
+	}
+	if _, _, err := s.Keyring("../test", testPassphrase); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("Keyring() error = %v, want %v", err, ErrInvalidName)
+	}
+}
+
+func TestKeystore_Unlock(t *testing.T) {
+	s := newTestKeystore(t)
+
+	if _, _, err := s.Keyring("test", ""); !errors.Is(err, ErrKeyLocked) {
+		t.Fatalf("Keyring() error = %v, want %v", err, ErrKeyLocked)
+	}
+	if err := s.Unlock("test", testPassphrase); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if _, info, err := s.Keyring("test", ""); err != nil || !equalsTestAddress(info.GetAddress()) {
+		t.Fatalf("Keyring() = %v, %v", info, err)
+	}
+	if err := s.Lock("test"); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	if _, _, err := s.Keyring("test", ""); !errors.Is(err, ErrKeyLocked) {
+		t.Fatalf("Keyring() error = %v, want %v", err, ErrKeyLocked)
+	}
+}
+
+func TestKeystore_FailedPassphrases(t *testing.T) {
+	s := newTestKeystore(t)
+
+	for i := 0; i < MaxAttempts; i++ {
+		if _, _, err := s.Keyring("test", "invalid"); !errors.Is(err, ErrBadPassphrase) {
+			t.Fatalf("Keyring() attempt %d error = %v, want %v", i, err, ErrBadPassphrase)
+		}
+	}
+
+	if _, _, err := s.Keyring("test", testPassphrase); !errors.Is(err, ErrTooManyFails) {
+		t.Fatalf("Keyring() error = %v, want %v", err, ErrTooManyFails)
+	}
+	if err := s.Unlock("test", testPassphrase); !errors.Is(err, ErrTooManyFails) {
+		t.Fatalf("Unlock() error = %v, want %v", err, ErrTooManyFails)
+	}
+
+	// The lockout is over once its duration has passed.
+	s.failures["test"].until = s.failures["test"].until.Add(-LockoutDuration)
+	if _, _, err := s.Keyring("test", testPassphrase); err != nil {
+		t.Fatalf("Keyring() error = %v", err)
+	}
+	if _, ok := s.failures["test"]; ok {
+		t.Fatalf("failures were not reset by a valid passphrase")
+	}
+}
@@ -4,21 +4,99 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	apicontext "github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/idempotency"
+	"github.com/solarlabsteam/sentinel-api-backend/jobs"
+	"github.com/solarlabsteam/sentinel-api-backend/keystore"
+	"github.com/solarlabsteam/sentinel-api-backend/middlewares"
 	"github.com/solarlabsteam/sentinel-api-backend/routes"
 	"github.com/solarlabsteam/sentinel-api-backend/types"
+	"github.com/solarlabsteam/sentinel-api-backend/webhooks"
 )
 
 const (
 	appName = "sentinelapi"
+
+	flagConfig              = "config"
+	flagListenAddress       = "listen-address"
+	flagChainID             = "chain-id"
+	flagRPCAddresses        = "rpc-addresses"
+	flagGas                 = "gas"
+	flagGasAdjustment       = "gas-adjustment"
+	flagGasPrices           = "gas-prices"
+	flagMaxQueryTries       = "max-query-tries"
+	flagCORSAllowedOrigins  = "cors-allowed-origins"
+	flagReadTimeout         = "read-timeout"
+	flagWriteTimeout        = "write-timeout"
+	flagNodeTimeout         = "node-timeout"
+	flagMaxBlockAge         = "max-block-age"
+	flagTxMaxRetries        = "tx-max-retries"
+	flagTxRetryBackoff      = "tx-retry-backoff"
+	flagRequestTimeout      = "request-timeout"
+	flagJobWorkers          = "job-workers"
+	flagJobQueueSize        = "job-queue-size"
+	flagJobTimeout          = "job-timeout"
+	flagJobTTL              = "job-ttl"
+	flagWebhookSecret       = "webhook-secret"
+	flagWebhookMaxRetries   = "webhook-max-retries"
+	flagWebhookRetryBackoff = "webhook-retry-backoff"
+	flagWebhookTimeout      = "webhook-timeout"
+	flagIdempotencyTTL      = "idempotency-ttl"
+	flagKeystoreDir         = "keystore-dir"
+	flagAdminToken          = "admin-token"
 )
 
+// readConfig merges the defaults, the configuration file, the environment variables
+// prefixed with SENTINELAPI_ and the command line flags, in increasing order of priority.
+func readConfig(cmd *cobra.Command) (*types.Config, error) {
+	v := viper.New()
+	v.SetEnvPrefix(appName)
+	v.AutomaticEnv()
+
+	for _, name := range []string{
+		flagListenAddress, flagChainID, flagRPCAddresses, flagGas, flagGasAdjustment, flagGasPrices,
+		flagMaxQueryTries, flagCORSAllowedOrigins, flagReadTimeout, flagWriteTimeout, flagNodeTimeout,
+		flagMaxBlockAge, flagTxMaxRetries, flagTxRetryBackoff, flagRequestTimeout, flagJobWorkers,
+		flagJobQueueSize, flagJobTimeout, flagJobTTL, flagWebhookSecret, flagWebhookMaxRetries,
+		flagWebhookRetryBackoff, flagWebhookTimeout, flagIdempotencyTTL, flagKeystoreDir, flagAdminToken,
+	} {
+		if err := v.BindPFlag(strings.ReplaceAll(name, "-", "_"), cmd.Flags().Lookup(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	if path, _ := cmd.Flags().GetString(flagConfig); path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := types.DefaultConfig()
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+
+	// PORT is set by the hosting platform and takes precedence over the configuration.
+	if port := os.Getenv("PORT"); port != "" {
+		cfg.ListenAddress = ":" + port
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
 func main() {
 	cmd := &cobra.Command{
 		Use:          appName,
@@ -40,22 +118,86 @@ func main() {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := apicontext.GetContextFromCmd(cmd)
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := apicontext.GetContextFromCmd(cmd).
+				WithConfig(cfg).
+				WithRPCAddresses(cfg.RPCAddresses).
+				WithJobs(jobs.NewQueue(cfg.JobWorkers, cfg.JobQueueSize, cfg.JobTimeout, cfg.JobTTL)).
+				WithWebhooks(webhooks.NewDispatcher(webhooks.NewRegistry(), cfg.WebhookSecret, cfg.WebhookMaxRetries, cfg.WebhookRetryBackoff, cfg.WebhookTimeout)).
+				WithIdempotency(idempotency.NewStore(cfg.IdempotencyTTL)).
+				WithKeystore(keystore.NewKeystore(cfg.KeystoreDir))
+			ctx.Endpoints().Start(cmd.Context(), apicontext.DefaultEndpointProbeInterval)
+			ctx.Jobs().Start(cmd.Context())
+			ctx.Webhooks().Start(cmd.Context())
+
+			corsCfg := cors.DefaultConfig()
+			corsCfg.AllowOrigins = cfg.CORSAllowedOrigins
+			corsCfg.AddAllowHeaders(middlewares.HeaderIdempotencyKey)
+			corsCfg.AddExposeHeaders(middlewares.HeaderIdempotentReplayed)
+
 			engine := gin.Default()
-			engine.Use(cors.Default())
+			engine.Use(cors.New(corsCfg))
+			engine.Use(middlewares.Config(cfg))
+			engine.Use(middlewares.Authenticate(cfg.AdminToken))
+			engine.Use(middlewares.Metrics())
+			engine.Use(middlewares.Timeout(cfg))
 
 			router := engine.Group("/api/v1")
 
 			routes.RegisterHealthRoutes(engine.Group("/"), ctx)
+			routes.RegisterMetricsRoutes(engine.Group("/"), ctx)
+			routes.RegisterJobRoutes(router, ctx)
 			routes.RegisterKeyRoutes(router, ctx)
+			routes.RegisterKeystoreRoutes(router.Group("", middlewares.Admin(cfg.AdminToken)), ctx)
 			routes.RegisterQueryRoutes(router, ctx)
 			routes.RegisterTxRoutes(router, ctx)
 			routes.RegisterVersionRoutes(router, ctx)
+			routes.RegisterWebhookRoutes(router.Group("", middlewares.Admin(cfg.AdminToken)), ctx)
 
-			return http.ListenAndServe(":"+os.Getenv("PORT"), engine)
+			server := &http.Server{
+				Addr:         cfg.ListenAddress,
+				Handler:      engine,
+				ReadTimeout:  cfg.ReadTimeout,
+				WriteTimeout: cfg.WriteTimeout,
+			}
+
+			return server.ListenAndServe()
 		},
 	}
 
+	defaultCfg := types.DefaultConfig()
+	cmd.Flags().String(flagConfig, "", "path to the configuration file (toml, yaml or json)")
+	cmd.Flags().String(flagListenAddress, defaultCfg.ListenAddress, "address to listen on")
+	cmd.Flags().String(flagChainID, defaultCfg.ChainID, "default chain ID for transactions")
+	cmd.Flags().StringSlice(flagRPCAddresses, defaultCfg.RPCAddresses, "default RPC endpoints")
+	cmd.Flags().Uint64(flagGas, defaultCfg.Gas, "default gas limit for transactions")
+	cmd.Flags().Float64(flagGasAdjustment, defaultCfg.GasAdjustment, "default gas adjustment for transactions")
+	cmd.Flags().String(flagGasPrices, defaultCfg.GasPrices, "default gas prices for transactions")
+	cmd.Flags().Int64(flagMaxQueryTries, defaultCfg.MaxQueryTries, "default number of tries to query a broadcast transaction")
+	cmd.Flags().StringSlice(flagCORSAllowedOrigins, defaultCfg.CORSAllowedOrigins, "allowed CORS origins")
+	cmd.Flags().Duration(flagReadTimeout, defaultCfg.ReadTimeout, "maximum duration for reading a request")
+	cmd.Flags().Duration(flagWriteTimeout, defaultCfg.WriteTimeout, "maximum duration for writing a response")
+	cmd.Flags().Duration(flagNodeTimeout, defaultCfg.NodeTimeout, "timeout for the requests to the nodes")
+	cmd.Flags().Duration(flagMaxBlockAge, defaultCfg.MaxBlockAge, "maximum age of the latest block before the chain is considered stalled")
+	cmd.Flags().Int64(flagTxMaxRetries, defaultCfg.TxMaxRetries, "number of retries of a transaction after a sequence mismatch or a transient RPC error")
+	cmd.Flags().Duration(flagTxRetryBackoff, defaultCfg.TxRetryBackoff, "delay before the first retry of a transaction, doubled before each of the next ones")
+	cmd.Flags().Duration(flagRequestTimeout, defaultCfg.RequestTimeout, "default deadline of a request")
+	cmd.Flags().Int(flagJobWorkers, defaultCfg.JobWorkers, "number of workers running the asynchronous transactions")
+	cmd.Flags().Int(flagJobQueueSize, defaultCfg.JobQueueSize, "maximum number of asynchronous transactions waiting for a worker")
+	cmd.Flags().Duration(flagJobTimeout, defaultCfg.JobTimeout, "deadline of an asynchronous transaction")
+	cmd.Flags().Duration(flagJobTTL, defaultCfg.JobTTL, "duration for which the result of an asynchronous transaction is kept")
+	cmd.Flags().String(flagWebhookSecret, defaultCfg.WebhookSecret, "key of the signatures of the webhook deliveries")
+	cmd.Flags().Int64(flagWebhookMaxRetries, defaultCfg.WebhookMaxRetries, "number of retries of a failed webhook delivery")
+	cmd.Flags().Duration(flagWebhookRetryBackoff, defaultCfg.WebhookRetryBackoff, "delay before the first retry of a webhook delivery, doubled before each of the next ones")
+	cmd.Flags().Duration(flagWebhookTimeout, defaultCfg.WebhookTimeout, "timeout of a webhook delivery")
+	cmd.Flags().Duration(flagIdempotencyTTL, defaultCfg.IdempotencyTTL, "duration for which the outcome of a request made with an idempotency key is kept")
+	cmd.Flags().String(flagKeystoreDir, defaultCfg.KeystoreDir, "directory of the encrypted keys, the keystore is disabled when empty")
+	cmd.Flags().String(flagAdminToken, defaultCfg.AdminToken, "bearer token of the admin routes, which are disabled when empty")
+
 	_ = cmd.ExecuteContext(
 		context.WithValue(
 			context.Background(),
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace = "sentinelapi"
+)
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, route and status code.",
+		},
+		[]string{"method", "route", "status"},
+	)
+	HTTPRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by method and route.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"method", "route"},
+	)
+
+	RPCCallDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "call_duration_seconds",
+			Help:      "Latency of Tendermint RPC calls by method.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method"},
+	)
+	RPCCallErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "call_errors_total",
+			Help:      "Number of failed Tendermint RPC calls by method.",
+		},
+		[]string{"method"},
+	)
+
+	TxBroadcastsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tx",
+			Name:      "broadcasts_total",
+			Help:      "Number of broadcast transactions by ABCI codespace and code.",
+		},
+		[]string{"codespace", "code"},
+	)
+	TxQueryPolls = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "tx",
+			Name:      "query_polls",
+			Help:      "Number of polls made while waiting for a transaction to be included.",
+			Buckets:   []float64{1, 2, 3, 5, 10, 20, 30, 60},
+		},
+	)
+	TxConfirmationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tx",
+			Name:      "confirmations_total",
+			Help:      "Number of included transactions by the way the inclusion was observed (websocket, query or polling).",
+		},
+		[]string{"source"},
+	)
+	TxAttempts = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "tx",
+			Name:      "attempts",
+			Help:      "Number of attempts made to build, sign and broadcast a transaction.",
+			Buckets:   []float64{1, 2, 3, 4, 5, 10},
+		},
+	)
+
+	JobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "total",
+			Help:      "Number of asynchronous jobs by status reached.",
+		},
+		[]string{"status"},
+	)
+
+	WebhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "webhooks",
+			Name:      "deliveries_total",
+			Help:      "Number of webhook deliveries by event type and outcome (success, failure or dropped).",
+		},
+		[]string{"event", "outcome"},
+	)
+
+	NodeCallDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "call_duration_seconds",
+			Help:      "Latency of the calls to the node remote URLs by endpoint and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"endpoint", "outcome"},
+	)
+)
+
+// ObserveRPCCall records the latency of an RPC call which started at the given time,
+// and counts it as an error when it failed.
+func ObserveRPCCall(method string, start time.Time, failed bool) {
+	RPCCallDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if failed {
+		RPCCallErrorsTotal.WithLabelValues(method).Inc()
+	}
+}
+
+func ObserveTxBroadcast(codespace string, code uint32) {
+	TxBroadcastsTotal.WithLabelValues(codespace, strconv.FormatUint(uint64(code), 10)).Inc()
+}
+
+func ObserveNodeCall(endpoint string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+
+	NodeCallDuration.WithLabelValues(endpoint, outcome).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+// isAdmin returns whether the request carries the token as a bearer token in the
+// Authorization header.
+func isAdmin(c *gin.Context, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	v, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(v), []byte(token)) == 1
+}
+
+// Authenticate marks the requests carrying the token as a bearer token in the
+// Authorization header as admin requests, without rejecting the other ones.
+func Authenticate(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(types.ContextKeyAdmin, isAdmin(c, token))
+		c.Next()
+	}
+}
+
+// Admin restricts the routes to the requests carrying the token as a bearer token in
+// the Authorization header, and rejects all of them when the token is empty.
+func Admin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			err := errors.New("admin routes are disabled")
+			abortWithError(c, types.NewError(types.ErrorCodeUnauthorized, err))
+			return
+		}
+		if !isAdmin(c, token) {
+			err := errors.New("invalid admin token")
+			abortWithError(c, types.NewError(types.ErrorCodeUnauthorized, err))
+			return
+		}
+
+		c.Next()
+	}
+}
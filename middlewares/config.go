@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+// Config makes the server configuration available to the request binders.
+func Config(cfg *types.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(types.ContextKeyConfig, cfg)
+		c.Next()
+	}
+}
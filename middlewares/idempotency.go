@@ -0,0 +1,161 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/idempotency"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyWriter keeps a copy of the response body.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// isFinalStatus reports whether a response is the one a request would get again, and
+// is replayed. Server errors, which include the timeouts, are not, nor are the client
+// errors which go away without changing the request, such as a locked key.
+func isFinalStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusRequestTimeout, http.StatusLocked, http.StatusTooManyRequests:
+		return false
+	}
+
+	return statusCode >= 200 && statusCode < 500
+}
+
+// idempotencyCaller identifies the caller of a request by the credentials it carries: the
+// bearer token and the signer of the transaction body. The requests carrying neither are
+// identified by the IP address of the caller. The keys are scoped to the callers, so that
+// the keys of a caller neither collide with nor reveal the requests of the others.
+func idempotencyCaller(c *gin.Context, body []byte) string {
+	var v struct {
+		KeyName       string `json:"key_name"`
+		KeyPassphrase string `json:"key_passphrase"`
+		Mnemonic      string `json:"mnemonic"`
+		BIP39Password string `json:"bip39_password"`
+		PrivateKey    string `json:"private_key"`
+		From          string `json:"from"`
+	}
+
+	// The body is validated by the handler.
+	_ = json.Unmarshal(body, &v)
+
+	caller := strings.Join(
+		[]string{c.GetHeader("Authorization"), v.KeyName, v.KeyPassphrase, v.Mnemonic, v.BIP39Password, v.PrivateKey, v.From},
+		"\n",
+	)
+	if strings.Trim(caller, "\n") == "" {
+		return c.ClientIP()
+	}
+
+	return caller
+}
+
+func abortWithError(c *gin.Context, v *types.Error) {
+	c.AbortWithStatusJSON(v.StatusCode(), types.NewResponseError(v))
+}
+
+// Idempotency replays the outcome of the first request made by the caller with the key
+// of the Idempotency-Key header, if any, instead of handling the request again. Requests
+// made with the key while the first one is in flight wait for its outcome. The key is
+// freed when the outcome is not final, so that the request can be retried. The keys are
+// stored hashed along with the caller, which holds secrets.
+func Idempotency(store *idempotency.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			err := fmt.Errorf("%s cannot be longer than %d characters", HeaderIdempotencyKey, maxIdempotencyKeyLength)
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := sha256.Sum256([]byte(idempotencyCaller(c, body) + "\n" + key))
+		key = hex.EncodeToString(scope[:])
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+
+		outcome, err := store.Begin(c.Request.Context(), key, hex.EncodeToString(hash.Sum(nil)))
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			abortWithError(c, types.NewError(types.ErrorCodeBadInput, err))
+			return
+		}
+		if err != nil {
+			abortWithError(c, types.AsError(err))
+			return
+		}
+		if outcome != nil {
+			c.Header(HeaderIdempotentReplayed, "true")
+			c.Data(outcome.StatusCode, outcome.ContentType, outcome.Body)
+			c.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				store.Abandon(key)
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if !isFinalStatus(writer.Status()) {
+			return
+		}
+
+		store.Complete(
+			key,
+			&idempotency.Outcome{
+				StatusCode:  writer.Status(),
+				ContentType: writer.Header().Get("Content-Type"),
+				Body:        writer.body.Bytes(),
+			},
+		)
+		completed = true
+	}
+}
@@ -0,0 +1,133 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/idempotency"
+)
+
+// newIdempotencyRouter returns a router whose handler responds with the given status
+// codes in turn, and the number of times the handler was called.
+func newIdempotencyRouter(statusCodes ...int) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	router := gin.New()
+	router.POST("/txs", Idempotency(idempotency.NewDefaultStore()), func(c *gin.Context) {
+		c.JSON(statusCodes[calls], gin.H{"call": calls})
+		calls++
+	})
+
+	return router, &calls
+}
+
+func doIdempotentRequest(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/txs", strings.NewReader(body))
+	req.Header.Set(HeaderIdempotencyKey, key)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	router, calls := newIdempotencyRouter(http.StatusOK, http.StatusOK)
+
+	first := doIdempotentRequest(router, "key", `{"a":1}`)
+	second := doIdempotentRequest(router, "key", `{"a":1}`)
+
+	if *calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", *calls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Fatalf("replayed %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatalf("%s header is not set", HeaderIdempotentReplayed)
+	}
+	if first.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Fatalf("%s header is set on the first response", HeaderIdempotentReplayed)
+	}
+}
+
+func TestIdempotency_KeyReused(t *testing.T) {
+	router, calls := newIdempotencyRouter(http.StatusOK, http.StatusOK)
+
+	doIdempotentRequest(router, "key", `{"a":1}`)
+	w := doIdempotentRequest(router, "key", `{"a":2}`)
+
+	if *calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", *calls)
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestIdempotency_NotFinal(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		replayed   bool
+	}{
+		{"accepted", http.StatusAccepted, true},
+		{"bad request", http.StatusBadRequest, true},
+		{"unprocessable", http.StatusUnprocessableEntity, true},
+		{"unauthorized", http.StatusUnauthorized, false},
+		{"locked", http.StatusLocked, false},
+		{"too many requests", http.StatusTooManyRequests, false},
+		{"bad gateway", http.StatusBadGateway, false},
+		{"service unavailable", http.StatusServiceUnavailable, false},
+		{"gateway timeout", http.StatusGatewayTimeout, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, calls := newIdempotencyRouter(tt.statusCode, http.StatusOK)
+
+			doIdempotentRequest(router, "key", `{}`)
+			w := doIdempotentRequest(router, "key", `{}`)
+
+			wantCalls, wantCode := 2, http.StatusOK
+			if tt.replayed {
+				wantCalls, wantCode = 1, tt.statusCode
+			}
+			if *calls != wantCalls || w.Code != wantCode {
+				t.Fatalf("handler calls = %d, status code = %d, want %d, %d", *calls, w.Code, wantCalls, wantCode)
+			}
+		})
+	}
+}
+
+func TestIdempotency_NoKey(t *testing.T) {
+	router, calls := newIdempotencyRouter(http.StatusOK, http.StatusOK)
+
+	doIdempotentRequest(router, "", `{}`)
+	doIdempotentRequest(router, "", `{}`)
+
+	if *calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", *calls)
+	}
+}
+
+func TestIdempotency_Callers(t *testing.T) {
+	router, calls := newIdempotencyRouter(http.StatusOK, http.StatusOK, http.StatusOK)
+
+	// The same key of other signers neither collides nor replays.
+	doIdempotentRequest(router, "key", `{"mnemonic":"first","amount":1}`)
+	w := doIdempotentRequest(router, "key", `{"mnemonic":"second","amount":2}`)
+	if *calls != 2 || w.Code != http.StatusOK || w.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Fatalf("handler calls = %d, status code = %d, want 2, %d", *calls, w.Code, http.StatusOK)
+	}
+
+	w = doIdempotentRequest(router, "key", `{"mnemonic":"second","amount":2}`)
+	if *calls != 2 || w.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatalf("handler calls = %d, want 2 and a replay", *calls)
+	}
+}
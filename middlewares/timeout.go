@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+// Timeout sets a deadline on the context of the request, using the timeout configured
+// for the matched route or the default request timeout otherwise.
+func Timeout(cfg *types.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := cfg.RequestTimeout
+		if v, ok := cfg.RouteTimeouts[c.FullPath()]; ok {
+			timeout = v
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type RequestGetJob struct {
+	URI struct {
+		ID string `uri:"id" binding:"uuid"`
+	}
+}
+
+func NewRequestGetJob(c *gin.Context) (req *RequestGetJob, err error) {
+	req = &RequestGetJob{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
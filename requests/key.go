@@ -1,6 +1,9 @@
 package requests
 
 import (
+	"fmt"
+	"math"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gin-gonic/gin"
 	hubtypes "github.com/sentinel-official/hub/types"
@@ -26,7 +29,7 @@ func NewRequestAddSessionKey(c *gin.Context) (req *RequestAddSessionKey, err err
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -52,3 +55,56 @@ func NewRequestAddSessionKey(c *gin.Context) (req *RequestAddSessionKey, err err
 
 	return req, err
 }
+
+type RequestDeriveKeys struct {
+	Query struct {
+		Algo     string `form:"algo,default=secp256k1" binding:"oneof=secp256k1 eth_secp256k1"`
+		CoinType uint32 `form:"coin_type,default=118"`
+		Account  uint32 `form:"account"`
+		Index    uint32 `form:"index"`
+
+		// Count is the number of keys derived, at the consecutive indexes starting
+		// from Index.
+		Count uint32 `form:"count,default=1" binding:"min=1,max=100"`
+	}
+	Body struct {
+		Mnemonic      string `json:"mnemonic" binding:"required"`
+		BIP39Password string `json:"bip39_password"`
+	}
+}
+
+func NewRequestDeriveKeys(c *gin.Context) (req *RequestDeriveKeys, err error) {
+	req = &RequestDeriveKeys{}
+	if err = c.ShouldBindQuery(&req.Query); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+	if uint64(req.Query.Index)+uint64(req.Query.Count) > math.MaxUint32+1 {
+		return nil, fmt.Errorf("index range overflows")
+	}
+
+	bindCoinType(c, req.Query.Algo, &req.Query.CoinType)
+	return req, nil
+}
+
+type RequestGenerateKey struct {
+	Query struct {
+		Algo        string `form:"algo,default=secp256k1" binding:"oneof=secp256k1 eth_secp256k1"`
+		CoinType    uint32 `form:"coin_type,default=118"`
+		Account     uint32 `form:"account"`
+		Index       uint32 `form:"index"`
+		EntropyBits int    `form:"entropy_bits,default=256" binding:"oneof=128 160 192 224 256"`
+	}
+}
+
+func NewRequestGenerateKey(c *gin.Context) (req *RequestGenerateKey, err error) {
+	req = &RequestGenerateKey{}
+	if err = c.ShouldBindQuery(&req.Query); err != nil {
+		return nil, err
+	}
+
+	bindCoinType(c, req.Query.Algo, &req.Query.CoinType)
+	return req, nil
+}
@@ -0,0 +1,68 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type RequestAddKeystoreKey struct {
+	Query struct {
+		Algo     string `form:"algo,default=secp256k1" binding:"oneof=secp256k1"`
+		CoinType uint32 `form:"coin_type,default=118"`
+		Account  uint32 `form:"account"`
+		Index    uint32 `form:"index"`
+	}
+	Body struct {
+		Name          string `json:"name" binding:"required"`
+		Passphrase    string `json:"passphrase" binding:"required,min=8"`
+		Mnemonic      string `json:"mnemonic"`
+		BIP39Password string `json:"bip39_password"`
+	}
+}
+
+func NewRequestAddKeystoreKey(c *gin.Context) (req *RequestAddKeystoreKey, err error) {
+	req = &RequestAddKeystoreKey{}
+	if err = c.ShouldBindQuery(&req.Query); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+type RequestKeystoreKey struct {
+	URI struct {
+		Name string `uri:"name"`
+	}
+}
+
+func NewRequestKeystoreKey(c *gin.Context) (req *RequestKeystoreKey, err error) {
+	req = &RequestKeystoreKey{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+type RequestUnlockKeystoreKey struct {
+	URI struct {
+		Name string `uri:"name"`
+	}
+	Body struct {
+		Passphrase string `json:"passphrase" binding:"required"`
+	}
+}
+
+func NewRequestUnlockKeystoreKey(c *gin.Context) (req *RequestUnlockKeystoreKey, err error) {
+	req = &RequestUnlockKeystoreKey{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
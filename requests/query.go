@@ -3,6 +3,8 @@ package requests
 import (
 	"encoding/base64"
 	"fmt"
+	"regexp"
+	"strings"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
@@ -10,6 +12,10 @@ import (
 	hubtypes "github.com/sentinel-official/hub/types"
 )
 
+var (
+	eventKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9_\-]+(\.[a-zA-Z0-9_\-]+)+$`)
+)
+
 type RequestGetAccount struct {
 	AccAddress sdk.AccAddress
 
@@ -17,7 +23,7 @@ type RequestGetAccount struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -46,7 +52,7 @@ type RequestGetBalancesForAccount struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -97,7 +103,7 @@ type RequestFeegrantAllowancesByGranter struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -148,7 +154,7 @@ type RequestFeegrantAllowances struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -200,7 +206,7 @@ type RequestGetSessionsForAccount struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -256,7 +262,7 @@ type RequestGetSubscriptionsForAccount struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -307,7 +313,7 @@ type RequestGetDeposits struct {
 	Pagination *query.PageRequest
 
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
 		Limit      uint64 `form:"limit,default=25" binding:"gt=0"`
@@ -348,7 +354,7 @@ type RequestGetDeposit struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -374,7 +380,7 @@ type RequestGetNodes struct {
 	Pagination *query.PageRequest
 
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -421,7 +427,7 @@ type RequestGetNode struct {
 		NodeAddress string `uri:"node_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -447,7 +453,7 @@ type RequestGetPlans struct {
 	Pagination *query.PageRequest
 
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -492,7 +498,7 @@ type RequestGetPlan struct {
 		ID uint64 `uri:"id" binding:"gt=0"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -513,7 +519,7 @@ type RequestGetProviders struct {
 	Status     hubtypes.Status
 
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -560,7 +566,7 @@ type RequestGetProvider struct {
 		ProvAddress string `uri:"prov_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -589,7 +595,7 @@ type RequestGetNodesForPlan struct {
 		ID uint64 `uri:"id" binding:"gt=0"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -641,7 +647,7 @@ type RequestGetPlansForProvider struct {
 		ProvAddress string `uri:"prov_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -693,7 +699,7 @@ type RequestGetSessions struct {
 	Pagination *query.PageRequest
 
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -738,7 +744,7 @@ type RequestGetSession struct {
 		ID uint64 `uri:"id" binding:"gt=0"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -759,7 +765,7 @@ type RequestGetSubscriptions struct {
 	Pagination *query.PageRequest
 
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Status     string `form:"status,default=Active" binding:"oneof=Active InactivePending Inactive"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
@@ -804,7 +810,7 @@ type RequestGetSubscription struct {
 		ID uint64 `uri:"id" binding:"gt=0"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -827,7 +833,7 @@ type RequestGetAllocationsForSubscription struct {
 		ID uint64 `uri:"id" binding:"gt=0"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 		Key        string `form:"key"`
 		Offset     uint64 `form:"offset"`
 		Limit      uint64 `form:"limit,default=25" binding:"gt=0"`
@@ -872,7 +878,7 @@ type RequestGetAllocationForSubscription struct {
 		AccAddress string `uri:"acc_address"`
 	}
 	Query struct {
-		RPCAddress string `form:"rpc_address" binding:"required"`
+		RPCAddress string `form:"rpc_address"`
 	}
 }
 
@@ -892,3 +898,81 @@ func NewRequestGetAllocationForSubscription(c *gin.Context) (req *RequestGetAllo
 
 	return req, nil
 }
+
+type RequestGetTx struct {
+	URI struct {
+		Hash string `uri:"hash" binding:"hexadecimal,len=64"`
+	}
+	Query struct {
+		RPCAddress string `form:"rpc_address"`
+	}
+}
+
+func NewRequestGetTx(c *gin.Context) (req *RequestGetTx, err error) {
+	req = &RequestGetTx{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindQuery(&req.Query); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+type RequestGetTxs struct {
+	Events  string
+	OrderBy string
+
+	Query struct {
+		RPCAddress string   `form:"rpc_address"`
+		Events     []string `form:"events"`
+		MinHeight  int64    `form:"min_height" binding:"gte=0"`
+		MaxHeight  int64    `form:"max_height" binding:"gte=0"`
+		Page       int      `form:"page,default=1" binding:"gt=0"`
+		Limit      int      `form:"limit,default=25" binding:"gt=0,lte=100"`
+		Reverse    bool     `form:"reverse"`
+	}
+}
+
+func NewRequestGetTxs(c *gin.Context) (req *RequestGetTxs, err error) {
+	req = &RequestGetTxs{}
+	if err = c.ShouldBindQuery(&req.Query); err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	for _, s := range req.Query.Events {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || !eventKeyRegexp.MatchString(key) || value == "" || strings.Contains(value, "'") {
+			return nil, fmt.Errorf("invalid event %s", s)
+		}
+
+		// Typed events such as sentinel.session.v2.EventStart store their attribute
+		// values JSON encoded, hence a string value is indexed with its quotes.
+		if strings.Count(key, ".") > 1 && !strings.HasPrefix(value, `"`) {
+			value = `"` + value + `"`
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s='%s'", key, value))
+	}
+
+	if req.Query.MinHeight > 0 {
+		conditions = append(conditions, fmt.Sprintf("tx.height>=%d", req.Query.MinHeight))
+	}
+	if req.Query.MaxHeight > 0 {
+		conditions = append(conditions, fmt.Sprintf("tx.height<=%d", req.Query.MaxHeight))
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("at least one of events, min_height and max_height is required")
+	}
+
+	req.Events = strings.Join(conditions, " AND ")
+
+	req.OrderBy = "asc"
+	if req.Query.Reverse {
+		req.OrderBy = "desc"
+	}
+
+	return req, nil
+}
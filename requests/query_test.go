@@ -0,0 +1,69 @@
+package requests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewRequestGetTxs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query   url.Values
+		want    string
+		wantErr bool
+	}{
+		{query: url.Values{"events": {"message.sender=sent1abc"}}, want: "message.sender='sent1abc'"},
+		{query: url.Values{"events": {"sentinel.session.v2.EventStart.address=sent1abc"}}, want: `sentinel.session.v2.EventStart.address='"sent1abc"'`},
+		{query: url.Values{"events": {`sentinel.session.v2.EventStart.id="1"`}}, want: `sentinel.session.v2.EventStart.id='"1"'`},
+		{query: url.Values{"min_height": {"10"}}, want: "tx.height>=10"},
+		{
+			query: url.Values{
+				"events":     {"message.action=/cosmos.bank.v1beta1.MsgSend", "transfer.amount=1udvpn"},
+				"min_height": {"10"},
+				"max_height": {"20"},
+			},
+			want: "message.action='/cosmos.bank.v1beta1.MsgSend' AND transfer.amount='1udvpn' AND tx.height>=10 AND tx.height<=20",
+		},
+		{query: url.Values{}, wantErr: true},
+		{query: url.Values{"events": {"message.sender=sent1' OR tx.height>0"}}, wantErr: true},
+		{query: url.Values{"events": {"message=sent1abc"}}, wantErr: true},
+		{query: url.Values{"events": {"message.sender OR=sent1abc"}}, wantErr: true},
+		{query: url.Values{"events": {"message.sender="}}, wantErr: true},
+		{query: url.Values{"events": {"message.sender"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/txs?"+tt.query.Encode(), nil)
+
+		req, err := NewRequestGetTxs(c)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("NewRequestGetTxs(%s) error = %v, want error %t", tt.query.Encode(), err, tt.wantErr)
+		}
+		if err == nil && req.Events != tt.want {
+			t.Fatalf("NewRequestGetTxs(%s) events = %s, want %s", tt.query.Encode(), req.Events, tt.want)
+		}
+	}
+}
+
+func TestNewRequestGetTxs_OrderBy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for query, want := range map[string]string{"": "asc", "&reverse=true": "desc"} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/txs?min_height=1"+query, nil)
+
+		req, err := NewRequestGetTxs(c)
+		if err != nil {
+			t.Fatalf("NewRequestGetTxs() error = %v", err)
+		}
+		if req.OrderBy != want {
+			t.Fatalf("NewRequestGetTxs() order by = %s, want %s", req.OrderBy, want)
+		}
+	}
+}
@@ -1,27 +1,43 @@
 package requests
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gin-gonic/gin"
 	hubtypes "github.com/sentinel-official/hub/types"
+
+	"github.com/solarlabsteam/sentinel-api-backend/crypto/ethsecp256k1"
+	"github.com/solarlabsteam/sentinel-api-backend/types"
 )
 
 type (
 	TxQuery struct {
+		Algo               string  `form:"algo,default=secp256k1" binding:"oneof=secp256k1"`
 		BroadcastMode      string  `form:"broadcast_mode,default=sync" binding:"oneof=async block sync"`
-		ChainID            string  `form:"chain_id,default=sentinelhub-2" binding:"required"`
+		ChainID            string  `form:"chain_id"`
 		CoinType           uint32  `form:"coin_type,default=118"`
 		Account            uint32  `form:"account"`
 		Index              uint32  `form:"index"`
-		GasAdjustment      float64 `form:"gas_adjustment,default=1.25" binding:"gt=0"`
-		GasPrices          string  `form:"gas_prices,default=0.1udvpn"`
-		Gas                uint64  `form:"gas,default=200000" binding:"gt=0"`
-		MaxQueryTries      int64   `form:"max_query_tries,default=60" binding:"gt=0"`
-		RPCAddress         string  `form:"rpc_address,default=https://rpc.sentinel.co:443" binding:"required"`
+		GasAdjustment      float64 `form:"gas_adjustment" binding:"gte=0"`
+		GasPrices          string  `form:"gas_prices"`
+		Gas                uint64  `form:"gas"`
+		MaxQueryTries      int64   `form:"max_query_tries" binding:"gte=0"`
+		RPCAddress         string  `form:"rpc_address"`
 		SimulateAndExecute bool    `form:"simulate_and_execute,default=true"`
+		GenerateOnly       bool    `form:"generate_only"`
+		SimulateOnly       bool    `form:"simulate_only"`
+
+		// Async runs the broadcast and the steps which follow it as a job, whose ID
+		// is returned right away.
+		Async bool `form:"async"`
+
+		// Admin is whether the request carries the admin token, which is set by the
+		// authenticate middleware and not bound.
+		Admin bool `form:"-"`
 	}
 	TxBody struct {
 		AuthzGranter  string `json:"authz_granter"`
@@ -31,10 +47,82 @@ type (
 		Memo          string `json:"memo"`
 		SignMode      string `json:"sign_mode"`
 		TimeoutHeight uint64 `json:"timeout_height"`
-		Mnemonic      string `json:"mnemonic" binding:"required"`
+		Mnemonic      string `json:"mnemonic"`
+
+		// KeyName references a key of the keystore, which takes the place of the mnemonic.
+		// The key is decrypted with KeyPassphrase, or must have been unlocked when it is empty,
+		// in which case the request must carry the admin token.
+		KeyName       string `json:"key_name"`
+		KeyPassphrase string `json:"key_passphrase"`
+
+		// PrivateKey is a hex encoded private key of the algorithm of the query, or an
+		// ASCII armored export decrypted with PrivateKeyPassphrase, which takes the place
+		// of the mnemonic.
+		PrivateKey           string `json:"private_key"`
+		PrivateKeyPassphrase string `json:"private_key_passphrase"`
+
+		// From is the address of the signer in the generate and simulate only modes,
+		// which takes the place of the mnemonic.
+		From string `json:"from"`
+
+		// CallbackURL is notified of the confirmation or the failure of the transaction.
+		// It must be an https URL of a public host.
+		CallbackURL string `json:"callback_url" binding:"omitempty,url,startswith=https://"`
 	}
 )
 
+// config returns the server configuration set by the config middleware.
+func config(c *gin.Context) *types.Config {
+	if v, ok := c.Get(types.ContextKeyConfig); ok {
+		return v.(*types.Config)
+	}
+
+	return types.DefaultConfig()
+}
+
+// bindCoinType defaults the coin type to the one of Ethereum for the eth_secp256k1 keys,
+// as the EVM wallets derive them.
+func bindCoinType(c *gin.Context, algo string, coinType *uint32) {
+	if _, ok := c.GetQuery("coin_type"); !ok && algo == ethsecp256k1.KeyType {
+		*coinType = ethsecp256k1.CoinType
+	}
+}
+
+// bind binds the query parameters and falls back to the server configuration
+// for the ones which are not set.
+func (q *TxQuery) bind(c *gin.Context) error {
+	if err := c.ShouldBindQuery(q); err != nil {
+		return err
+	}
+	if q.GenerateOnly && q.SimulateOnly {
+		return fmt.Errorf("generate_only and simulate_only cannot be both set")
+	}
+	if q.Async && (q.GenerateOnly || q.SimulateOnly) {
+		return fmt.Errorf("async cannot be set with generate_only or simulate_only")
+	}
+
+	q.Admin = c.GetBool(types.ContextKeyAdmin)
+
+	cfg := config(c)
+	if q.ChainID == "" {
+		q.ChainID = cfg.ChainID
+	}
+	if q.GasAdjustment == 0 {
+		q.GasAdjustment = cfg.GasAdjustment
+	}
+	if q.GasPrices == "" {
+		q.GasPrices = cfg.GasPrices
+	}
+	if q.Gas == 0 {
+		q.Gas = cfg.Gas
+	}
+	if q.MaxQueryTries == 0 {
+		q.MaxQueryTries = cfg.MaxQueryTries
+	}
+
+	return nil
+}
+
 type RequestTxAuthzGrant struct {
 	AuthzGranter sdk.AccAddress
 	FeeGranter   sdk.AccAddress
@@ -52,7 +140,7 @@ type RequestTxAuthzGrant struct {
 
 func NewRequestTxAuthzGrant(c *gin.Context) (req *RequestTxAuthzGrant, err error) {
 	req = &RequestTxAuthzGrant{}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -112,7 +200,7 @@ type RequestTxFeegrantGrantAllowance struct {
 
 func NewRequestTxFeegrantGrantAllowance(c *gin.Context) (req *RequestTxFeegrantGrantAllowance, err error) {
 	req = &RequestTxFeegrantGrantAllowance{}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -186,7 +274,7 @@ type RequestTxBankSend struct {
 
 func NewRequestTxBankSend(c *gin.Context) (req *RequestTxBankSend, err error) {
 	req = &RequestTxBankSend{}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -237,6 +325,229 @@ func NewRequestTxBankSend(c *gin.Context) (req *RequestTxBankSend, err error) {
 	return req, err
 }
 
+// parseWholeCoins parses the coins as sdk.ParseCoinsNormalized does, converting the
+// denominations with registered decimal units to their base ones, but rejects the amounts
+// which are not whole base units rather than truncating them.
+func parseWholeCoins(s string) (sdk.Coins, error) {
+	decCoins, err := sdk.ParseDecCoins(s)
+	if err != nil {
+		return nil, err
+	}
+
+	coins := make(sdk.Coins, 0, len(decCoins))
+	for _, decCoin := range decCoins {
+		coin, change := sdk.NormalizeDecCoin(decCoin).TruncateDecimal()
+		if !change.IsZero() {
+			return nil, fmt.Errorf("invalid coin %s: amount must be a whole number of base units", decCoin)
+		}
+
+		coins = append(coins, coin)
+	}
+
+	coins = coins.Sort()
+	if err := coins.Validate(); err != nil {
+		return nil, err
+	}
+
+	return coins, nil
+}
+
+// parseCoins returns nil coins for an empty string, which the update of the details of
+// a node takes as prices which are left unchanged.
+func parseCoins(s string) (sdk.Coins, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	return parseWholeCoins(s)
+}
+
+type RequestTxNodeRegister struct {
+	AuthzGranter   sdk.AccAddress
+	FeeGranter     sdk.AccAddress
+	GasPrices      sdk.DecCoins
+	GigabytePrices sdk.Coins
+	HourlyPrices   sdk.Coins
+
+	Query TxQuery
+	Body  struct {
+		TxBody
+		GigabytePrices string `json:"gigabyte_prices" binding:"required"`
+		HourlyPrices   string `json:"hourly_prices" binding:"required"`
+		RemoteURL      string `json:"remote_url" binding:"required,url"`
+	}
+}
+
+func NewRequestTxNodeRegister(c *gin.Context) (req *RequestTxNodeRegister, err error) {
+	req = &RequestTxNodeRegister{}
+	if err = req.Query.bind(c); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	if req.Body.AuthzGranter != "" {
+		req.AuthzGranter, err = sdk.AccAddressFromBech32(req.Body.AuthzGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Body.FeeGranter != "" {
+		req.FeeGranter, err = sdk.AccAddressFromBech32(req.Body.FeeGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.GasPrices, err = sdk.ParseDecCoins(req.Query.GasPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	req.GigabytePrices, err = parseWholeCoins(req.Body.GigabytePrices)
+	if err != nil {
+		return nil, err
+	}
+
+	req.HourlyPrices, err = parseWholeCoins(req.Body.HourlyPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, err
+}
+
+type RequestTxNodeUpdateDetails struct {
+	AuthzGranter   sdk.AccAddress
+	FeeGranter     sdk.AccAddress
+	GasPrices      sdk.DecCoins
+	GigabytePrices sdk.Coins
+	HourlyPrices   sdk.Coins
+	NodeAddress    hubtypes.NodeAddress
+
+	URI struct {
+		NodeAddress string `uri:"node_address"`
+	}
+	Query TxQuery
+	Body  struct {
+		TxBody
+
+		// The details which are empty are left unchanged.
+		GigabytePrices string `json:"gigabyte_prices"`
+		HourlyPrices   string `json:"hourly_prices"`
+		RemoteURL      string `json:"remote_url" binding:"omitempty,url"`
+	}
+}
+
+func NewRequestTxNodeUpdateDetails(c *gin.Context) (req *RequestTxNodeUpdateDetails, err error) {
+	req = &RequestTxNodeUpdateDetails{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+	if err = req.Query.bind(c); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	req.NodeAddress, err = hubtypes.NodeAddressFromBech32(req.URI.NodeAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body.AuthzGranter != "" {
+		req.AuthzGranter, err = sdk.AccAddressFromBech32(req.Body.AuthzGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Body.FeeGranter != "" {
+		req.FeeGranter, err = sdk.AccAddressFromBech32(req.Body.FeeGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.GasPrices, err = sdk.ParseDecCoins(req.Query.GasPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	req.GigabytePrices, err = parseCoins(req.Body.GigabytePrices)
+	if err != nil {
+		return nil, err
+	}
+
+	req.HourlyPrices, err = parseCoins(req.Body.HourlyPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, err
+}
+
+type RequestTxNodeUpdateStatus struct {
+	AuthzGranter sdk.AccAddress
+	FeeGranter   sdk.AccAddress
+	GasPrices    sdk.DecCoins
+	NodeAddress  hubtypes.NodeAddress
+	Status       hubtypes.Status
+
+	URI struct {
+		NodeAddress string `uri:"node_address"`
+	}
+	Query TxQuery
+	Body  struct {
+		TxBody
+		Status string `json:"status" binding:"required,oneof=active inactive"`
+	}
+}
+
+func NewRequestTxNodeUpdateStatus(c *gin.Context) (req *RequestTxNodeUpdateStatus, err error) {
+	req = &RequestTxNodeUpdateStatus{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+	if err = req.Query.bind(c); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	req.NodeAddress, err = hubtypes.NodeAddressFromBech32(req.URI.NodeAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body.AuthzGranter != "" {
+		req.AuthzGranter, err = sdk.AccAddressFromBech32(req.Body.AuthzGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Body.FeeGranter != "" {
+		req.FeeGranter, err = sdk.AccAddressFromBech32(req.Body.FeeGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.GasPrices, err = sdk.ParseDecCoins(req.Query.GasPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Status = hubtypes.StatusFromString(req.Body.Status)
+
+	return req, err
+}
+
 type RequestTxNodeSubscribe struct {
 	AuthzGranter sdk.AccAddress
 	FeeGranter   sdk.AccAddress
@@ -260,7 +571,7 @@ func NewRequestTxNodeSubscribe(c *gin.Context) (req *RequestTxNodeSubscribe, err
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -311,7 +622,7 @@ type RequestTxPlanCreate struct {
 
 func NewRequestTxPlanCreate(c *gin.Context) (req *RequestTxPlanCreate, err error) {
 	req = &RequestTxPlanCreate{}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -337,7 +648,7 @@ func NewRequestTxPlanCreate(c *gin.Context) (req *RequestTxPlanCreate, err error
 		return nil, err
 	}
 
-	req.Prices, err = sdk.ParseCoinsNormalized(req.Body.Prices)
+	req.Prices, err = parseWholeCoins(req.Body.Prices)
 	if err != nil {
 		return nil, err
 	}
@@ -366,7 +677,7 @@ func NewRequestTxPlanUpdateStatus(c *gin.Context) (req *RequestTxPlanUpdateStatu
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -418,7 +729,7 @@ func NewRequestTxPlanLinkNode(c *gin.Context) (req *RequestTxPlanLinkNode, err e
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -477,7 +788,7 @@ func NewRequestTxPlanUnlinkNode(c *gin.Context) (req *RequestTxPlanUnlinkNode, e
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -531,7 +842,7 @@ func NewRequestTxPlanSubscribe(c *gin.Context) (req *RequestTxPlanSubscribe, err
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -583,7 +894,7 @@ func NewRequestTxSubscriptionAllocate(c *gin.Context) (req *RequestTxSubscriptio
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -650,7 +961,7 @@ func NewRequestTxSessionStart(c *gin.Context) (req *RequestTxSessionStart, err e
 	if err = c.ShouldBindUri(&req.URI); err != nil {
 		return nil, err
 	}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -684,6 +995,75 @@ func NewRequestTxSessionStart(c *gin.Context) (req *RequestTxSessionStart, err e
 	return req, err
 }
 
+type RequestTxSessionEnd struct {
+	AuthzGranter sdk.AccAddress
+	FeeGranter   sdk.AccAddress
+	GasPrices    sdk.DecCoins
+	IDs          []uint64
+
+	URI struct {
+		ID uint64 `uri:"id"`
+	}
+	Query TxQuery
+	Body  struct {
+		TxBody
+		IDs    []uint64 `json:"ids"`
+		Rating uint64   `json:"rating" binding:"lte=10"`
+
+		// All ends all the active sessions of the account, along with the given ones.
+		All bool `json:"all"`
+	}
+}
+
+func NewRequestTxSessionEnd(c *gin.Context) (req *RequestTxSessionEnd, err error) {
+	req = &RequestTxSessionEnd{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+	if err = req.Query.bind(c); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	if req.URI.ID != 0 {
+		req.IDs = append(req.IDs, req.URI.ID)
+	}
+	for _, id := range req.Body.IDs {
+		if id == 0 {
+			return nil, fmt.Errorf("ids cannot contain 0")
+		}
+		if id != req.URI.ID {
+			req.IDs = append(req.IDs, id)
+		}
+	}
+	if len(req.IDs) == 0 && !req.Body.All {
+		return nil, fmt.Errorf("ids cannot be empty unless all is set")
+	}
+
+	if req.Body.AuthzGranter != "" {
+		req.AuthzGranter, err = sdk.AccAddressFromBech32(req.Body.AuthzGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Body.FeeGranter != "" {
+		req.FeeGranter, err = sdk.AccAddressFromBech32(req.Body.FeeGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.GasPrices, err = sdk.ParseDecCoins(req.Query.GasPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, err
+}
+
 type RequestTxSubscribe struct {
 	AuthzGranter  sdk.AccAddress
 	FeeGranter    sdk.AccAddress
@@ -703,7 +1083,7 @@ type RequestTxSubscribe struct {
 
 func NewRequestTxSubscribe(c *gin.Context) (req *RequestTxSubscribe, err error) {
 	req = &RequestTxSubscribe{}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -765,7 +1145,7 @@ type RequestTxSubscriptionCancel struct {
 
 func NewRequestTxSubscriptionCancel(c *gin.Context) (req *RequestTxSubscriptionCancel, err error) {
 	req = &RequestTxSubscriptionCancel{}
-	if err = c.ShouldBindQuery(&req.Query); err != nil {
+	if err = req.Query.bind(c); err != nil {
 		return nil, err
 	}
 	if err = c.ShouldBindJSON(&req.Body); err != nil {
@@ -793,3 +1173,90 @@ func NewRequestTxSubscriptionCancel(c *gin.Context) (req *RequestTxSubscriptionC
 
 	return req, err
 }
+
+type RequestTxBroadcast struct {
+	TxBytes []byte
+
+	Query struct {
+		BroadcastMode string `form:"broadcast_mode,default=sync" binding:"oneof=async block sync"`
+		MaxQueryTries int64  `form:"max_query_tries" binding:"gte=0"`
+		RPCAddress    string `form:"rpc_address"`
+	}
+	Body struct {
+		TxBytes     string          `json:"tx_bytes"`
+		Tx          json.RawMessage `json:"tx"`
+		CallbackURL string          `json:"callback_url" binding:"omitempty,url,startswith=https://"`
+	}
+}
+
+func NewRequestTxBroadcast(c *gin.Context) (req *RequestTxBroadcast, err error) {
+	req = &RequestTxBroadcast{}
+	if err = c.ShouldBindQuery(&req.Query); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	if req.Query.MaxQueryTries == 0 {
+		req.Query.MaxQueryTries = config(c).MaxQueryTries
+	}
+
+	if (req.Body.TxBytes == "") == (len(req.Body.Tx) == 0) {
+		return nil, fmt.Errorf("exactly one of tx_bytes and tx is required")
+	}
+
+	if req.Body.TxBytes != "" {
+		req.TxBytes, err = base64.StdEncoding.DecodeString(req.Body.TxBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+type RequestTxMessages struct {
+	AuthzGranter sdk.AccAddress
+	FeeGranter   sdk.AccAddress
+	GasPrices    sdk.DecCoins
+
+	Query TxQuery
+	Body  struct {
+		TxBody
+
+		// Messages are proto JSON encoded Any values, which are resolved by their @type.
+		Messages []json.RawMessage `json:"messages" binding:"required,min=1"`
+	}
+}
+
+func NewRequestTxMessages(c *gin.Context) (req *RequestTxMessages, err error) {
+	req = &RequestTxMessages{}
+	if err = req.Query.bind(c); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	if req.Body.AuthzGranter != "" {
+		req.AuthzGranter, err = sdk.AccAddressFromBech32(req.Body.AuthzGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Body.FeeGranter != "" {
+		req.FeeGranter, err = sdk.AccAddressFromBech32(req.Body.FeeGranter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.GasPrices, err = sdk.ParseDecCoins(req.Query.GasPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
@@ -0,0 +1,107 @@
+package requests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseCoins(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    string
+		wantNil bool
+		wantErr bool
+	}{
+		{s: "", wantNil: true},
+		{s: "1udvpn", want: "1udvpn"},
+		{s: "2udvpn,1uatom", want: "1uatom,2udvpn"},
+		{s: "1.0udvpn", want: "1udvpn"},
+		{s: "1.5udvpn", wantErr: true},
+		{s: "1udvpn,0.5uatom", wantErr: true},
+		{s: "udvpn", wantErr: true},
+		{s: "1udvpn,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCoins(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("parseCoins(%q) error = %v, want error %t", tt.s, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if (got == nil) != tt.wantNil {
+			t.Fatalf("parseCoins(%q) = %#v, want nil %t", tt.s, got, tt.wantNil)
+		}
+		if !tt.wantNil && got.String() != tt.want {
+			t.Fatalf("parseCoins(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestNewRequestTxNodeRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			body: `{"mnemonic":"m","gigabyte_prices":"1udvpn","hourly_prices":"2udvpn","remote_url":"https://1.2.3.4:8080"}`,
+		},
+		{
+			name:    "empty gigabyte_prices",
+			body:    `{"mnemonic":"m","gigabyte_prices":"","hourly_prices":"2udvpn","remote_url":"https://1.2.3.4:8080"}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing hourly_prices",
+			body:    `{"mnemonic":"m","gigabyte_prices":"1udvpn","remote_url":"https://1.2.3.4:8080"}`,
+			wantErr: true,
+		},
+		{
+			name:    "fractional gigabyte_prices",
+			body:    `{"mnemonic":"m","gigabyte_prices":"1.5udvpn","hourly_prices":"2udvpn","remote_url":"https://1.2.3.4:8080"}`,
+			wantErr: true,
+		},
+		{
+			name: "https callback_url",
+			body: `{"mnemonic":"m","gigabyte_prices":"1udvpn","hourly_prices":"2udvpn","remote_url":"https://1.2.3.4:8080","callback_url":"https://example.com/hook"}`,
+		},
+		{
+			name:    "http callback_url",
+			body:    `{"mnemonic":"m","gigabyte_prices":"1udvpn","hourly_prices":"2udvpn","remote_url":"https://1.2.3.4:8080","callback_url":"http://10.0.0.1/hook"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid hourly_prices",
+			body:    `{"mnemonic":"m","gigabyte_prices":"1udvpn","hourly_prices":"udvpn","remote_url":"https://1.2.3.4:8080"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/nodes", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			req, err := NewRequestTxNodeRegister(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRequestTxNodeRegister() error = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if req.GigabytePrices.String() != "1udvpn" || req.HourlyPrices.String() != "2udvpn" {
+				t.Fatalf("NewRequestTxNodeRegister() prices = %s, %s", req.GigabytePrices, req.HourlyPrices)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type RequestAddWebhook struct {
+	Body struct {
+		URL    string   `json:"url" binding:"required,url"`
+		Events []string `json:"events" binding:"required,min=1,dive,oneof=tx.confirmed tx.failed session.key_accepted"`
+	}
+}
+
+func NewRequestAddWebhook(c *gin.Context) (req *RequestAddWebhook, err error) {
+	req = &RequestAddWebhook{}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+type RequestDeleteWebhook struct {
+	URI struct {
+		ID string `uri:"id" binding:"uuid"`
+	}
+}
+
+func NewRequestDeleteWebhook(c *gin.Context) (req *RequestDeleteWebhook, err error) {
+	req = &RequestDeleteWebhook{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
@@ -1,8 +1,50 @@
 package responses
 
+import (
+	cryptohd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
+	hubtypes "github.com/sentinel-official/hub/types"
+)
+
 type ResponseAddSessionKey struct {
 	NodeType   float64 `json:"node_type"`
 	UID        string  `json:"uid,omitempty"`
 	PrivateKey string  `json:"private_key,omitempty"`
 	Result     string  `json:"result"`
 }
+
+type ResponseKey struct {
+	HDPath      string `json:"hd_path"`
+	Index       uint32 `json:"index"`
+	Algo        string `json:"algo"`
+	AccAddress  string `json:"acc_address"`
+	NodeAddress string `json:"node_address"`
+	ProvAddress string `json:"prov_address"`
+	PubKey      string `json:"pub_key"`
+}
+
+// NewResponseKey returns the addresses of the key, which was derived at the path, in
+// the account, node and provider forms.
+func NewResponseKey(v keyring.Info, path *cryptohd.BIP44Params) (*ResponseKey, error) {
+	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, v.GetPubKey())
+	if err != nil {
+		return nil, err
+	}
+
+	addr := v.GetAddress()
+	return &ResponseKey{
+		HDPath:      path.String(),
+		Index:       path.AddressIndex,
+		Algo:        string(v.GetAlgo()),
+		AccAddress:  addr.String(),
+		NodeAddress: hubtypes.NodeAddress(addr).String(),
+		ProvAddress: hubtypes.ProvAddress(addr).String(),
+		PubKey:      pubKey,
+	}, nil
+}
+
+type ResponseGenerateKey struct {
+	Mnemonic string       `json:"mnemonic"`
+	Key      *ResponseKey `json:"key"`
+}
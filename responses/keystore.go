@@ -0,0 +1,30 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/solarlabsteam/sentinel-api-backend/keystore"
+)
+
+type ResponseKeystoreKey struct {
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	PubKey    string    `json:"pub_key"`
+	Algo      string    `json:"algo"`
+	CreatedAt time.Time `json:"created_at"`
+	Unlocked  bool      `json:"unlocked"`
+
+	// Mnemonic is only returned once, when the key is generated.
+	Mnemonic string `json:"mnemonic,omitempty"`
+}
+
+func NewResponseKeystoreKey(v *keystore.Key, unlocked bool) *ResponseKeystoreKey {
+	return &ResponseKeystoreKey{
+		Name:      v.Name,
+		Address:   v.Address,
+		PubKey:    v.PubKey,
+		Algo:      v.Algo,
+		CreatedAt: v.CreatedAt,
+		Unlocked:  unlocked,
+	}
+}
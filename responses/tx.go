@@ -0,0 +1,209 @@
+package responses
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+	eventutils "github.com/solarlabsteam/sentinel-api-backend/utils/event"
+)
+
+// The states of a transaction. A transaction which is neither in a block nor in the
+// mempool of the queried endpoint is not_found, which may also mean it was dropped.
+const (
+	TxStatusCommitted = "committed"
+	TxStatusPending   = "pending"
+	TxStatusNotFound  = "not_found"
+)
+
+type ResponseTx struct {
+	Hash      string          `json:"hash"`
+	Status    string          `json:"status"`
+	Height    int64           `json:"height,omitempty"`
+	Index     uint32          `json:"index,omitempty"`
+	Code      uint32          `json:"code"`
+	Codespace string          `json:"codespace,omitempty"`
+	Log       string          `json:"log,omitempty"`
+	GasWanted int64           `json:"gas_wanted,omitempty"`
+	GasUsed   int64           `json:"gas_used,omitempty"`
+	Tx        json.RawMessage `json:"tx,omitempty"`
+	Events    []*types.Event  `json:"events,omitempty"`
+}
+
+// NewResponseTx decodes the transaction of the result, rendering its messages with
+// the interface registry of the transaction config.
+func NewResponseTx(txConfig client.TxConfig, v *coretypes.ResultTx) (*ResponseTx, error) {
+	tx, err := txConfig.TxDecoder()(v.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := txConfig.TxJSONEncoder()(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &ResponseTx{
+		Hash:      v.Hash.String(),
+		Status:    TxStatusCommitted,
+		Height:    v.Height,
+		Index:     v.Index,
+		Code:      v.TxResult.Code,
+		Codespace: v.TxResult.Codespace,
+		Log:       v.TxResult.Log,
+		GasWanted: v.TxResult.GasWanted,
+		GasUsed:   v.TxResult.GasUsed,
+		Tx:        buf,
+	}
+
+	for i := 0; i < len(v.TxResult.Events); i++ {
+		item.Events = append(item.Events, types.NewEventFromABCIEvent(&v.TxResult.Events[i]))
+	}
+
+	return item, nil
+}
+
+// ResponseTxResult is the decoded result of a transaction broadcast by the server, along
+// with the number of times it was built and broadcast. The IDs of the subscriptions, plans
+// and sessions created by the transaction, and the allocations it granted, are extracted
+// from its events.
+type ResponseTxResult struct {
+	Hash      string            `json:"hash"`
+	Height    int64             `json:"height"`
+	Index     uint32            `json:"index"`
+	Code      uint32            `json:"code"`
+	Codespace string            `json:"codespace,omitempty"`
+	GasWanted int64             `json:"gas_wanted"`
+	GasUsed   int64             `json:"gas_used"`
+	Fee       sdk.Coins         `json:"fee,omitempty"`
+	Memo      string            `json:"memo,omitempty"`
+	Messages  []json.RawMessage `json:"messages,omitempty"`
+	Events    []*types.Event    `json:"events"`
+	Attempts  int64             `json:"attempts"`
+
+	SubscriptionIDs []uint64                 `json:"subscription_ids,omitempty"`
+	PlanIDs         []uint64                 `json:"plan_ids,omitempty"`
+	SessionIDs      []uint64                 `json:"session_ids,omitempty"`
+	Allocations     []*eventutils.Allocation `json:"allocations,omitempty"`
+}
+
+// NewResponseTxResult decodes the result of a transaction included in a block. As the
+// transaction is committed, the result is always returned: a transaction which cannot be
+// decoded is reported without its fee and messages, and the IDs and allocations which
+// cannot be extracted from its events are left empty, which the returned error tells about.
+func NewResponseTxResult(cdc codec.JSONCodec, txConfig client.TxConfig, v *coretypes.ResultTx, attempts int64) (*ResponseTxResult, error) {
+	item := &ResponseTxResult{
+		Hash:      v.Hash.String(),
+		Height:    v.Height,
+		Index:     v.Index,
+		Code:      v.TxResult.Code,
+		Codespace: v.TxResult.Codespace,
+		GasWanted: v.TxResult.GasWanted,
+		GasUsed:   v.TxResult.GasUsed,
+		Events:    make([]*types.Event, 0, len(v.TxResult.Events)),
+		Attempts:  attempts,
+	}
+
+	var errs []error
+	if tx, err := txConfig.TxDecoder()(v.Tx); err == nil {
+		if feeTx, ok := tx.(sdk.FeeTx); ok {
+			item.Fee = feeTx.GetFee()
+		}
+		if memoTx, ok := tx.(sdk.TxWithMemo); ok {
+			item.Memo = memoTx.GetMemo()
+		}
+
+		for _, msg := range tx.GetMsgs() {
+			buf, err := cdc.MarshalInterfaceJSON(msg)
+			if err != nil {
+				errs = append(errs, err)
+				item.Messages = nil
+				break
+			}
+
+			item.Messages = append(item.Messages, buf)
+		}
+	}
+
+	for i := 0; i < len(v.TxResult.Events); i++ {
+		item.Events = append(item.Events, types.NewEventFromABCIEvent(&v.TxResult.Events[i]))
+	}
+
+	var err error
+	if item.SubscriptionIDs, err = eventutils.GetSubscriptionIDsFromABCIEvents(v.TxResult.Events); err != nil {
+		errs = append(errs, err)
+	}
+	if item.PlanIDs, err = eventutils.GetPlanIDsFromABCIEvents(v.TxResult.Events); err != nil {
+		errs = append(errs, err)
+	}
+	if item.SessionIDs, err = eventutils.GetSessionIDsFromABCIEvents(v.TxResult.Events); err != nil {
+		errs = append(errs, err)
+	}
+	if item.Allocations, err = eventutils.GetAllocationsFromABCIEvents(v.TxResult.Events); err != nil {
+		errs = append(errs, err)
+	}
+
+	return item, errors.Join(errs...)
+}
+
+type ResponseTxSearch struct {
+	Total int           `json:"total"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Txs   []*ResponseTx `json:"txs"`
+}
+
+// ResponseUnsignedTx is a transaction to be signed by the caller, either over the
+// amino JSON sign document or over the sign document of the direct mode, which is
+// made of the body and the auth info of the transaction, the chain ID and the account number.
+// The amino JSON sign document is omitted when a message has no amino encoding.
+type ResponseUnsignedTx struct {
+	ChainID       string          `json:"chain_id"`
+	AccountNumber uint64          `json:"account_number"`
+	Sequence      uint64          `json:"sequence"`
+	Tx            json.RawMessage `json:"tx"`
+	AminoSignDoc  json.RawMessage `json:"amino_sign_doc,omitempty"`
+}
+
+func NewResponseUnsignedTx(txConfig client.TxConfig, tx sdk.Tx, signerData *authsigning.SignerData) (*ResponseUnsignedTx, error) {
+	buf, err := txConfig.TxJSONEncoder()(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &ResponseUnsignedTx{
+		ChainID:       signerData.ChainID,
+		AccountNumber: signerData.AccountNumber,
+		Sequence:      signerData.Sequence,
+		Tx:            buf,
+	}
+
+	// The handler of the amino JSON sign mode panics on the messages which are not
+	// legacy messages.
+	for _, msg := range tx.GetMsgs() {
+		if _, ok := msg.(legacytx.LegacyMsg); !ok {
+			return item, nil
+		}
+	}
+
+	item.AminoSignDoc, err = txConfig.SignModeHandler().GetSignBytes(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON, *signerData, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+type ResponseSimulateTx struct {
+	GasUsed  uint64    `json:"gas_used"`
+	GasLimit uint64    `json:"gas_limit"`
+	Fees     sdk.Coins `json:"fees"`
+}
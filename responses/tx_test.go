@@ -0,0 +1,50 @@
+package responses
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+func TestNewResponseTxResult(t *testing.T) {
+	var (
+		cdc      = codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+		txConfig = authtx.NewTxConfig(cdc, authtx.DefaultSignModes)
+	)
+
+	v := &coretypes.ResultTx{
+		Height: 10,
+		Tx:     []byte("invalid"),
+		TxResult: abcitypes.ResponseDeliverTx{
+			Events: []abcitypes.Event{
+				{
+					Type:       "sentinel.plan.v2.EventCreate",
+					Attributes: []abcitypes.EventAttribute{{Key: []byte("id"), Value: []byte(`"1"`)}},
+				},
+				{
+					Type:       "sentinel.session.v2.EventStart",
+					Attributes: []abcitypes.EventAttribute{{Key: []byte("id"), Value: []byte(`"invalid"`)}},
+				},
+			},
+		},
+	}
+
+	// The committed transaction is reported even though its session ID is invalid.
+	result, err := NewResponseTxResult(cdc, txConfig, v, 1)
+	if err == nil {
+		t.Fatalf("NewResponseTxResult() error = nil")
+	}
+	if result == nil {
+		t.Fatalf("NewResponseTxResult() = nil")
+	}
+	if result.Height != 10 || len(result.Events) != 2 || result.Messages != nil {
+		t.Fatalf("NewResponseTxResult() = %+v", result)
+	}
+	if len(result.PlanIDs) != 1 || result.PlanIDs[0] != 1 || result.SessionIDs != nil {
+		t.Fatalf("NewResponseTxResult() IDs = %v, %v, want [1], []", result.PlanIDs, result.SessionIDs)
+	}
+}
@@ -1,14 +1,21 @@
 package routes
 
 import (
-	"github.com/gin-gonic/gin"
 	"net/http"
 
+	"github.com/gin-gonic/gin"
+
 	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/handlers"
 )
 
 func RegisterHealthRoutes(router gin.IRouter, ctx context.Context) {
 	router.GET("/robots933456.txt", func(c *gin.Context) {
 		c.JSON(http.StatusOK, &struct{}{})
 	})
+
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, &struct{}{})
+	})
+	router.GET("/readyz", handlers.HandlerReadiness(ctx))
 }
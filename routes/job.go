@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/handlers"
+)
+
+func RegisterJobRoutes(router gin.IRouter, ctx context.Context) {
+	router.GET("/jobs/:id", handlers.HandlerGetJob(ctx))
+}
@@ -5,8 +5,17 @@ import (
 
 	"github.com/solarlabsteam/sentinel-api-backend/context"
 	"github.com/solarlabsteam/sentinel-api-backend/handlers"
+	"github.com/solarlabsteam/sentinel-api-backend/middlewares"
 )
 
 func RegisterKeyRoutes(router gin.IRouter, ctx context.Context) {
+	// The derivation and generation of keys are exempt from the Idempotency-Key header.
+	// They change no state, so that retrying them is safe, and replaying them would keep
+	// the generated mnemonics in memory for the TTL of the outcomes.
+	router.POST("/keys/derive", handlers.HandlerDeriveKeys(ctx))
+	router.POST("/keys/generate", handlers.HandlerGenerateKey(ctx))
+
+	router = router.Group("", middlewares.Idempotency(ctx.Idempotency()))
+
 	router.POST("/nodes/:node_address/sessions/:id/keys", handlers.HandlerAddSessionKey(ctx))
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/handlers"
+)
+
+func RegisterKeystoreRoutes(router gin.IRouter, ctx context.Context) {
+	router.GET("/keystore/keys", handlers.HandlerGetKeystoreKeys(ctx))
+	router.POST("/keystore/keys", handlers.HandlerAddKeystoreKey(ctx))
+	router.DELETE("/keystore/keys/:name", handlers.HandlerDeleteKeystoreKey(ctx))
+	router.POST("/keystore/keys/:name/unlock", handlers.HandlerUnlockKeystoreKey(ctx))
+	router.POST("/keystore/keys/:name/lock", handlers.HandlerLockKeystoreKey(ctx))
+}
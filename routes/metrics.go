@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+)
+
+func RegisterMetricsRoutes(router gin.IRouter, _ context.Context) {
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+}
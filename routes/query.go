@@ -37,4 +37,7 @@ func RegisterQueryRoutes(router gin.IRouter, ctx context.Context) {
 	router.GET("/subscriptions/:id", handlers.HandlerGetSubscription(ctx))
 	router.GET("/subscriptions/:id/allocations", handlers.HandlerGetAllocationsForSubscription(ctx))
 	router.GET("/subscriptions/:id/allocations/:acc_address", handlers.HandlerGetAllocationForSubscription(ctx))
+
+	router.GET("/txs", handlers.HandlerGetTxs(ctx))
+	router.GET("/txs/:hash", handlers.HandlerGetTx(ctx))
 }
@@ -5,15 +5,21 @@ import (
 
 	"github.com/solarlabsteam/sentinel-api-backend/context"
 	"github.com/solarlabsteam/sentinel-api-backend/handlers"
+	"github.com/solarlabsteam/sentinel-api-backend/middlewares"
 )
 
 func RegisterTxRoutes(router gin.IRouter, ctx context.Context) {
+	router = router.Group("", middlewares.Idempotency(ctx.Idempotency()))
+
 	router.POST("/authzgrants", handlers.HandlerTxAuthzGrant(ctx))
 
 	router.POST("/balances", handlers.HandlerTxBankSend(ctx))
 
 	router.POST("/feegrants", handlers.HandlerTxFeegrantGrantAllowance(ctx))
 
+	router.POST("/nodes", handlers.HandlerTxNodeRegister(ctx))
+	router.PUT("/nodes/:node_address", handlers.HandlerTxNodeUpdateDetails(ctx))
+	router.PUT("/nodes/:node_address/status", handlers.HandlerTxNodeUpdateStatus(ctx))
 	router.POST("/nodes/:node_address/subscriptions", handlers.HandlerTxNodeSubscribe(ctx))
 
 	router.POST("/plans", handlers.HandlerTxPlanCreate(ctx))
@@ -27,4 +33,11 @@ func RegisterTxRoutes(router gin.IRouter, ctx context.Context) {
 	router.PUT("/subscriptions", handlers.HandlerTxSubscriptionCancel(ctx))
 
 	router.POST("/subscriptions/:id/nodes/:node_address/sessions", handlers.HandlerTxSessionStart(ctx))
+	router.PUT("/sessions", handlers.HandlerTxSessionEnd(ctx))
+	router.PUT("/sessions/:id", handlers.HandlerTxSessionEnd(ctx))
+	router.DELETE("/sessions", handlers.HandlerTxSessionEnd(ctx))
+	router.DELETE("/sessions/:id", handlers.HandlerTxSessionEnd(ctx))
+
+	router.POST("/txs", handlers.HandlerTxBroadcast(ctx))
+	router.POST("/txs/messages", handlers.HandlerTxMessages(ctx))
 }
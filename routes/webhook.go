@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/solarlabsteam/sentinel-api-backend/context"
+	"github.com/solarlabsteam/sentinel-api-backend/handlers"
+)
+
+func RegisterWebhookRoutes(router gin.IRouter, ctx context.Context) {
+	router.GET("/webhooks", handlers.HandlerGetWebhooks(ctx))
+	router.POST("/webhooks", handlers.HandlerAddWebhook(ctx))
+	router.DELETE("/webhooks/:id", handlers.HandlerDeleteWebhook(ctx))
+}
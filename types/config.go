@@ -0,0 +1,174 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	ContextKeyConfig = "config"
+	ContextKeyAdmin  = "admin"
+)
+
+type Config struct {
+	ListenAddress      string        `mapstructure:"listen_address"`
+	ChainID            string        `mapstructure:"chain_id"`
+	RPCAddresses       []string      `mapstructure:"rpc_addresses"`
+	Gas                uint64        `mapstructure:"gas"`
+	GasAdjustment      float64       `mapstructure:"gas_adjustment"`
+	GasPrices          string        `mapstructure:"gas_prices"`
+	MaxQueryTries      int64         `mapstructure:"max_query_tries"`
+	CORSAllowedOrigins []string      `mapstructure:"cors_allowed_origins"`
+	ReadTimeout        time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout       time.Duration `mapstructure:"write_timeout"`
+	NodeTimeout        time.Duration `mapstructure:"node_timeout"`
+	MaxBlockAge        time.Duration `mapstructure:"max_block_age"`
+
+	// TxMaxRetries is the number of times a transaction is built, signed and broadcast
+	// again after a sequence mismatch or a transient RPC error, waiting TxRetryBackoff
+	// before the first retry and doubling it before each of the next ones.
+	TxMaxRetries   int64         `mapstructure:"tx_max_retries"`
+	TxRetryBackoff time.Duration `mapstructure:"tx_retry_backoff"`
+
+	// RequestTimeout is the deadline of a request, unless RouteTimeouts has an entry
+	// for its route (e.g. "/api/v1/nodes/:node_address/sessions/:id/keys").
+	RequestTimeout time.Duration            `mapstructure:"request_timeout"`
+	RouteTimeouts  map[string]time.Duration `mapstructure:"route_timeouts"`
+
+	// The transactions requested with async=true run as jobs in a pool of JobWorkers
+	// workers, with at most JobQueueSize jobs waiting. A job is given JobTimeout to
+	// finish and its result is kept for JobTTL after it finished.
+	JobWorkers   int           `mapstructure:"job_workers"`
+	JobQueueSize int           `mapstructure:"job_queue_size"`
+	JobTimeout   time.Duration `mapstructure:"job_timeout"`
+	JobTTL       time.Duration `mapstructure:"job_ttl"`
+
+	// WebhookSecret is the key of the signatures of the webhook deliveries, which are
+	// not signed when it is empty. A failed delivery is retried WebhookMaxRetries times,
+	// waiting WebhookRetryBackoff before the first retry and doubling it before each of
+	// the next ones.
+	WebhookSecret       string        `mapstructure:"webhook_secret"`
+	WebhookMaxRetries   int64         `mapstructure:"webhook_max_retries"`
+	WebhookRetryBackoff time.Duration `mapstructure:"webhook_retry_backoff"`
+	WebhookTimeout      time.Duration `mapstructure:"webhook_timeout"`
+
+	// IdempotencyTTL is the duration for which the outcome of a transaction request made
+	// with an Idempotency-Key header is replayed to the requests made with the same key.
+	// Server errors and timeouts are not replayed, so that the requests can be retried.
+	IdempotencyTTL time.Duration `mapstructure:"idempotency_ttl"`
+
+	// KeystoreDir is the directory of the encrypted keys which the transaction requests
+	// can reference by name, and the keystore is disabled when it is empty. The keystore
+	// is managed through the admin routes, which require the AdminToken as a bearer token
+	// and are disabled when it is empty. The unlocked keys require the AdminToken too.
+	KeystoreDir string `mapstructure:"keystore_dir"`
+	AdminToken  string `mapstructure:"admin_token"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		ListenAddress:       ":8080",
+		ChainID:             "sentinelhub-2",
+		RPCAddresses:        []string{"https://rpc.sentinel.co:443"},
+		Gas:                 200000,
+		GasAdjustment:       1.25,
+		GasPrices:           "0.1udvpn",
+		MaxQueryTries:       60,
+		CORSAllowedOrigins:  []string{"*"},
+		ReadTimeout:         30 * time.Second,
+		WriteTimeout:        120 * time.Second,
+		NodeTimeout:         15 * time.Second,
+		MaxBlockAge:         2 * time.Minute,
+		TxMaxRetries:        3,
+		TxRetryBackoff:      time.Second,
+		RequestTimeout:      90 * time.Second,
+		RouteTimeouts:       map[string]time.Duration{},
+		JobWorkers:          8,
+		JobQueueSize:        256,
+		JobTimeout:          5 * time.Minute,
+		JobTTL:              time.Hour,
+		WebhookMaxRetries:   5,
+		WebhookRetryBackoff: time.Second,
+		WebhookTimeout:      10 * time.Second,
+		IdempotencyTTL:      24 * time.Hour,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.ListenAddress == "" {
+		return errors.New("listen_address cannot be empty")
+	}
+	if c.ChainID == "" {
+		return errors.New("chain_id cannot be empty")
+	}
+	if len(c.RPCAddresses) == 0 {
+		return errors.New("rpc_addresses cannot be empty")
+	}
+	if c.Gas == 0 {
+		return errors.New("gas must be positive")
+	}
+	if c.GasAdjustment <= 0 {
+		return errors.New("gas_adjustment must be positive")
+	}
+	if _, err := sdk.ParseDecCoins(c.GasPrices); err != nil {
+		return err
+	}
+	if c.MaxQueryTries <= 0 {
+		return errors.New("max_query_tries must be positive")
+	}
+	if c.ReadTimeout < 0 {
+		return errors.New("read_timeout cannot be negative")
+	}
+	if c.WriteTimeout < 0 {
+		return errors.New("write_timeout cannot be negative")
+	}
+	if c.NodeTimeout <= 0 {
+		return errors.New("node_timeout must be positive")
+	}
+	if c.MaxBlockAge <= 0 {
+		return errors.New("max_block_age must be positive")
+	}
+	if c.TxMaxRetries < 0 {
+		return errors.New("tx_max_retries cannot be negative")
+	}
+	if c.TxRetryBackoff < 0 {
+		return errors.New("tx_retry_backoff cannot be negative")
+	}
+	if c.RequestTimeout <= 0 {
+		return errors.New("request_timeout must be positive")
+	}
+	for route, timeout := range c.RouteTimeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("route_timeouts for %s must be positive", route)
+		}
+	}
+	if c.JobWorkers <= 0 {
+		return errors.New("job_workers must be positive")
+	}
+	if c.JobQueueSize <= 0 {
+		return errors.New("job_queue_size must be positive")
+	}
+	if c.JobTimeout <= 0 {
+		return errors.New("job_timeout must be positive")
+	}
+	if c.JobTTL <= 0 {
+		return errors.New("job_ttl must be positive")
+	}
+	if c.WebhookMaxRetries < 0 {
+		return errors.New("webhook_max_retries cannot be negative")
+	}
+	if c.WebhookRetryBackoff < 0 {
+		return errors.New("webhook_retry_backoff cannot be negative")
+	}
+	if c.WebhookTimeout <= 0 {
+		return errors.New("webhook_timeout must be positive")
+	}
+	if c.IdempotencyTTL <= 0 {
+		return errors.New("idempotency_ttl must be positive")
+	}
+
+	return nil
+}
@@ -29,6 +29,8 @@ import (
 	custommint "github.com/sentinel-official/hub/x/mint"
 	"github.com/sentinel-official/hub/x/swap"
 	"github.com/sentinel-official/hub/x/vpn"
+
+	"github.com/solarlabsteam/sentinel-api-backend/crypto/ethsecp256k1"
 )
 
 type EncodingConfig struct {
@@ -88,6 +90,8 @@ func DefaultEncodingConfig() EncodingConfig {
 
 	sdkstd.RegisterLegacyAminoCodec(config.Amino)
 	sdkstd.RegisterInterfaces(config.InterfaceRegistry)
+	ethsecp256k1.RegisterLegacyAminoCodec(config.Amino)
+	ethsecp256k1.RegisterInterfaces(config.InterfaceRegistry)
 	ModuleBasics.RegisterLegacyAminoCodec(config.Amino)
 	ModuleBasics.RegisterInterfaces(config.InterfaceRegistry)
 
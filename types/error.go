@@ -0,0 +1,151 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The codes of the errors returned by the API. They are part of the API and must not be changed.
+const (
+	ErrorCodeBadInput          = "bad_input"
+	ErrorCodeNotFound          = "not_found"
+	ErrorCodeAccountNotFound   = "account_not_found"
+	ErrorCodeInsufficientFunds = "insufficient_funds"
+	ErrorCodeRPCUnavailable    = "rpc_unavailable"
+	ErrorCodeTxRejected        = "tx_rejected"
+	ErrorCodeTxFailed          = "tx_failed"
+	ErrorCodeNodeUnreachable   = "node_unreachable"
+	ErrorCodeNodeRejectedKey   = "node_rejected_key"
+	ErrorCodeTimeout           = "timeout"
+	ErrorCodeQueueFull         = "queue_full"
+	ErrorCodeUnauthorized      = "unauthorized"
+	ErrorCodeKeyLocked         = "key_locked"
+	ErrorCodeTooManyAttempts   = "too_many_attempts"
+	ErrorCodeInternal          = "internal"
+)
+
+var (
+	errorStatusCodes = map[string]int{
+		ErrorCodeBadInput:          http.StatusBadRequest,
+		ErrorCodeNotFound:          http.StatusNotFound,
+		ErrorCodeAccountNotFound:   http.StatusNotFound,
+		ErrorCodeInsufficientFunds: http.StatusUnprocessableEntity,
+		ErrorCodeRPCUnavailable:    http.StatusBadGateway,
+		ErrorCodeTxRejected:        http.StatusUnprocessableEntity,
+		ErrorCodeTxFailed:          http.StatusUnprocessableEntity,
+		ErrorCodeNodeUnreachable:   http.StatusBadGateway,
+		ErrorCodeNodeRejectedKey:   http.StatusBadGateway,
+		ErrorCodeTimeout:           http.StatusGatewayTimeout,
+		ErrorCodeQueueFull:         http.StatusServiceUnavailable,
+		ErrorCodeUnauthorized:      http.StatusUnauthorized,
+		ErrorCodeKeyLocked:         http.StatusLocked,
+		ErrorCodeTooManyAttempts:   http.StatusTooManyRequests,
+		ErrorCodeInternal:          http.StatusInternalServerError,
+	}
+)
+
+// Error is an entry of the error catalogue. Codespace and ABCICode are set when the
+// error was returned by the chain for a transaction.
+type Error struct {
+	Code      string `json:"code"`
+	Codespace string `json:"codespace,omitempty"`
+	ABCICode  uint32 `json:"abci_code,omitempty"`
+	Message   string `json:"message"`
+
+	// Attempts is the number of times a transaction was built and broadcast before
+	// the error, if the server signed it.
+	Attempts int64 `json:"attempts,omitempty"`
+}
+
+func NewError(code string, err error) *Error {
+	return &Error{
+		Code:    code,
+		Message: err.Error(),
+	}
+}
+
+// NewErrorFromABCI returns an error for a transaction the chain rejected (tx_rejected)
+// or failed to execute (tx_failed), or insufficient_funds when the fees or the funds
+// were not enough.
+func NewErrorFromABCI(code, codespace string, abciCode uint32, log string) *Error {
+	if isInsufficientFunds(codespace, abciCode) {
+		code = ErrorCodeInsufficientFunds
+	}
+
+	return &Error{
+		Code:      code,
+		Codespace: codespace,
+		ABCICode:  abciCode,
+		Message:   log,
+	}
+}
+
+// AsError returns the catalogue entry of the error. Errors which are not already an *Error
+// are classified as errors returned by an RPC call.
+func AsError(err error) *Error {
+	var v *Error
+	if errors.As(err, &v) {
+		return v
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return NewError(ErrorCodeTimeout, err)
+	}
+
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.NotFound:
+			return NewError(ErrorCodeNotFound, err)
+		case codes.InvalidArgument, codes.Unauthenticated:
+			return NewError(ErrorCodeBadInput, err)
+		default:
+			return NewError(ErrorCodeInternal, err)
+		}
+	}
+
+	return NewError(ErrorCodeRPCUnavailable, err)
+}
+
+// NewErrorFromSimulation returns an error for a transaction whose simulation failed. The
+// ABCI code is not available for simulations, so insufficient funds are detected from the log.
+func NewErrorFromSimulation(err error) *Error {
+	if _, ok := status.FromError(err); !ok {
+		return AsError(err)
+	}
+
+	code := ErrorCodeTxRejected
+	if strings.Contains(err.Error(), sdkerrors.ErrInsufficientFunds.Error()) ||
+		strings.Contains(err.Error(), sdkerrors.ErrInsufficientFee.Error()) {
+		code = ErrorCodeInsufficientFunds
+	}
+
+	return NewError(code, err)
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) StatusCode() int {
+	if v, ok := errorStatusCodes[e.Code]; ok {
+		return v
+	}
+
+	return http.StatusInternalServerError
+}
+
+func isInsufficientFunds(codespace string, code uint32) bool {
+	for _, err := range []*sdkerrors.Error{sdkerrors.ErrInsufficientFunds, sdkerrors.ErrInsufficientFee} {
+		if codespace == err.Codespace() && code == err.ABCICode() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,17 +1,5 @@
 package types
 
-type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-func NewError(code int, message string) *Error {
-	return &Error{
-		Code:    code,
-		Message: message,
-	}
-}
-
 type Response struct {
 	Success bool        `json:"success"`
 	Error   *Error      `json:"error,omitempty"`
@@ -26,8 +14,8 @@ func NewResponse(err *Error, res interface{}) *Response {
 	}
 }
 
-func NewResponseError(code int, v error) *Response {
-	return NewResponse(NewError(code, v.Error()), nil)
+func NewResponseError(v *Error) *Response {
+	return NewResponse(v, nil)
 }
 
 func NewResponseResult(v interface{}) *Response {
@@ -0,0 +1,41 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+func parseID(item *types.Event, key string) (uint64, error) {
+	id, err := strconv.ParseUint(item.Attributes[key], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid attribute %s of event %s: %w", key, item.Type, err)
+	}
+
+	return id, nil
+}
+
+// getIDsFromABCIEvents returns the IDs held by the attribute of the events of the
+// given types, in the order of the events.
+func getIDsFromABCIEvents(items []abcitypes.Event, key string, eventTypes ...string) ([]uint64, error) {
+	var ids []uint64
+	for i := 0; i < len(items); i++ {
+		for _, eventType := range eventTypes {
+			if items[i].Type != eventType {
+				continue
+			}
+
+			id, err := parseID(types.NewEventFromABCIEvent(&items[i]), key)
+			if err != nil {
+				return nil, err
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
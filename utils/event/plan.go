@@ -0,0 +1,10 @@
+package event
+
+import (
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// GetPlanIDsFromABCIEvents returns the IDs of the plans created by the transaction.
+func GetPlanIDsFromABCIEvents(items []abcitypes.Event) ([]uint64, error) {
+	return getIDsFromABCIEvents(items, "id", "sentinel.plan.v2.EventCreate")
+}
@@ -1,8 +1,6 @@
 package event
 
 import (
-	"strconv"
-
 	abcitypes "github.com/tendermint/tendermint/abci/types"
 
 	"github.com/solarlabsteam/sentinel-api-backend/types"
@@ -18,5 +16,11 @@ func GetSessionIDFromABCIEvents(items []abcitypes.Event) (uint64, error) {
 		return 0, err
 	}
 
-	return strconv.ParseUint(item.Attributes["id"], 10, 64)
+	return parseID(item, "id")
+}
+
+// GetSessionIDsFromABCIEvents returns the IDs of the sessions started by the
+// transaction.
+func GetSessionIDsFromABCIEvents(items []abcitypes.Event) ([]uint64, error) {
+	return getIDsFromABCIEvents(items, "id", "sentinel.session.v2.EventStart")
 }
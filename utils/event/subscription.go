@@ -0,0 +1,66 @@
+package event
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+type Allocation struct {
+	ID            uint64  `json:"id"`
+	Address       string  `json:"address"`
+	GrantedBytes  sdk.Int `json:"granted_bytes"`
+	UtilisedBytes sdk.Int `json:"utilised_bytes"`
+}
+
+// GetSubscriptionIDsFromABCIEvents returns the IDs of the subscriptions to the nodes and
+// to the plans created by the transaction.
+func GetSubscriptionIDsFromABCIEvents(items []abcitypes.Event) ([]uint64, error) {
+	return getIDsFromABCIEvents(
+		items, "id",
+		"sentinel.node.v2.EventCreateSubscription", "sentinel.plan.v2.EventCreateSubscription",
+	)
+}
+
+// GetAllocationsFromABCIEvents returns the allocations of the subscriptions granted by
+// the transaction.
+func GetAllocationsFromABCIEvents(items []abcitypes.Event) ([]*Allocation, error) {
+	var allocations []*Allocation
+	for i := 0; i < len(items); i++ {
+		if items[i].Type != "sentinel.subscription.v2.EventAllocate" {
+			continue
+		}
+
+		item := types.NewEventFromABCIEvent(&items[i])
+
+		id, err := parseID(item, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		grantedBytes, ok := sdk.NewIntFromString(item.Attributes["granted_bytes"])
+		if !ok {
+			return nil, fmt.Errorf("invalid attribute granted_bytes of event %s", item.Type)
+		}
+
+		utilisedBytes, ok := sdk.NewIntFromString(item.Attributes["utilised_bytes"])
+		if !ok {
+			return nil, fmt.Errorf("invalid attribute utilised_bytes of event %s", item.Type)
+		}
+
+		allocations = append(
+			allocations,
+			&Allocation{
+				ID:            id,
+				Address:       item.Attributes["address"],
+				GrantedBytes:  grantedBytes,
+				UtilisedBytes: utilisedBytes,
+			},
+		)
+	}
+
+	return allocations, nil
+}
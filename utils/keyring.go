@@ -1,26 +1,156 @@
 package utils
 
 import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/crypto"
 	cryptohd "github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	bip39 "github.com/cosmos/go-bip39"
 	uuid2 "github.com/hashicorp/go-uuid"
+
+	"github.com/solarlabsteam/sentinel-api-backend/crypto/ethsecp256k1"
+)
+
+const (
+	armorPrefix = "-----BEGIN"
 )
 
-func NewInMemoryKey(mnemonic string, coinType, account, index uint32, bip39Password string) (keyring.Keyring, keyring.Info, error) {
+var (
+	// SigningAlgos are the algorithms of the keys which can be derived or imported.
+	SigningAlgos = keyring.SigningAlgoList{cryptohd.Secp256k1, ethsecp256k1.EthSecp256k1}
+)
+
+// KeySource is what a key is resolved from, either a mnemonic with the HD path of the
+// key, or a private key. A private key is either hex encoded or exported in the ASCII
+// armored format, in which case it is decrypted with the passphrase and has its own
+// algorithm.
+type KeySource struct {
+	Algo          string
+	Mnemonic      string
+	BIP39Password string
+	CoinType      uint32
+	Account       uint32
+	Index         uint32
+
+	PrivateKey           string
+	PrivateKeyPassphrase string
+}
+
+// NewInMemoryKeyring returns an in-memory keyring supporting the signing algorithms.
+func NewInMemoryKeyring() keyring.Keyring {
+	return keyring.NewInMemory(
+		func(options *keyring.Options) {
+			options.SupportedAlgos = SigningAlgos
+		},
+	)
+}
+
+// NewSigningAlgo returns the signing algorithm with the given name, which defaults
+// to secp256k1.
+func NewSigningAlgo(name string) (keyring.SignatureAlgo, error) {
+	if name == "" {
+		return cryptohd.Secp256k1, nil
+	}
+
+	return keyring.NewSigningAlgoFromString(name, SigningAlgos)
+}
+
+// NewMnemonic returns a new BIP39 mnemonic of the given entropy size in bits, which is
+// a multiple of 32 between 128 and 256.
+func NewMnemonic(entropyBits int) (string, error) {
+	entropy, err := bip39.NewEntropy(entropyBits)
+	if err != nil {
+		return "", err
+	}
+
+	return bip39.NewMnemonic(entropy)
+}
+
+// NewKey returns an in-memory keyring holding the key resolved from the source.
+func NewKey(src *KeySource) (keyring.Keyring, keyring.Info, error) {
+	if src.PrivateKey != "" {
+		return NewInMemoryKeyFromPrivKey(src.PrivateKey, src.PrivateKeyPassphrase, src.Algo)
+	}
+
+	return NewInMemoryKey(src.Mnemonic, src.CoinType, src.Account, src.Index, src.BIP39Password, src.Algo)
+}
+
+func NewInMemoryKey(mnemonic string, coinType, account, index uint32, bip39Password, algo string) (keyring.Keyring, keyring.Info, error) {
 	uuid, err := uuid2.GenerateUUID()
 	if err != nil {
 		return nil, nil, err
 	}
 
+	signingAlgo, err := NewSigningAlgo(algo)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var (
-		kr   = keyring.NewInMemory()
+		kr   = NewInMemoryKeyring()
 		path = cryptohd.CreateHDPath(coinType, account, index)
 	)
 
-	key, err := kr.NewAccount(uuid, mnemonic, bip39Password, path.String(), cryptohd.Secp256k1)
+	key, err := kr.NewAccount(uuid, mnemonic, bip39Password, path.String(), signingAlgo)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	return kr, key, nil
 }
+
+// NewInMemoryKeyFromPrivKey imports a hex encoded private key of the given algorithm,
+// or an armored one encrypted with the passphrase.
+func NewInMemoryKeyFromPrivKey(privKey, passphrase, algo string) (keyring.Keyring, keyring.Info, error) {
+	uuid, err := uuid2.GenerateUUID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	armor := privKey
+	if !strings.HasPrefix(strings.TrimSpace(privKey), armorPrefix) {
+		signingAlgo, err := NewSigningAlgo(algo)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key, err := decodePrivKey(privKey, signingAlgo)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The keyring only imports armored keys, so the key is armored with the
+		// passphrase it is imported with.
+		passphrase = uuid
+		armor = crypto.EncryptArmorPrivKey(key, passphrase, string(signingAlgo.Name()))
+	}
+
+	kr := NewInMemoryKeyring()
+	if err := kr.ImportPrivKey(uuid, armor, passphrase); err != nil {
+		return nil, nil, err
+	}
+
+	key, err := kr.Key(uuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return kr, key, nil
+}
+
+func decodePrivKey(s string, algo keyring.SignatureAlgo) (cryptotypes.PrivKey, error) {
+	buf, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex private key: %w", err)
+	}
+	if len(buf) != secp256k1.PrivKeySize {
+		return nil, fmt.Errorf("private key must be %d bytes long", secp256k1.PrivKeySize)
+	}
+
+	return algo.Generate()(buf), nil
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	cryptohd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+
+	"github.com/solarlabsteam/sentinel-api-backend/crypto/ethsecp256k1"
+)
+
+const (
+	testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+)
+
+func TestNewKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     *KeySource
+		algo    cryptohd.PubKeyType
+		address string
+	}{
+		{
+			name:    "secp256k1",
+			src:     &KeySource{Mnemonic: testMnemonic, CoinType: 118},
+			algo:    cryptohd.Secp256k1Type,
+			address: "28ff5c6d57d8cfd492b6fb42614536ed648e01fd",
+		},
+		{
+			// The first address of the test mnemonic in the EVM wallets.
+			name:    "eth_secp256k1",
+			src:     &KeySource{Algo: ethsecp256k1.KeyType, Mnemonic: testMnemonic, CoinType: ethsecp256k1.CoinType},
+			algo:    ethsecp256k1.PubKeyType,
+			address: "9858effd232b4033e47d90003d41ec34ecaeda94",
+		},
+		{
+			name:    "eth_secp256k1 hex",
+			src:     &KeySource{Algo: ethsecp256k1.KeyType, PrivateKey: "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"},
+			algo:    ethsecp256k1.PubKeyType,
+			address: "2c7536e3605d9c16a7a3d7b1898e529396a65c23",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, info, err := NewKey(tt.src)
+			if err != nil {
+				t.Fatalf("NewKey() error = %v", err)
+			}
+			if info.GetAlgo() != tt.algo {
+				t.Fatalf("NewKey() algo = %s, want %s", info.GetAlgo(), tt.algo)
+			}
+			if got := hex.EncodeToString(info.GetAddress()); got != tt.address {
+				t.Fatalf("NewKey() address = %s, want %s", got, tt.address)
+			}
+		})
+	}
+}
+
+func TestNewInMemoryKeyFromPrivKey_Armor(t *testing.T) {
+	for _, algo := range []string{"", ethsecp256k1.KeyType} {
+		kr, info, err := NewInMemoryKey(testMnemonic, 60, 0, 0, "", algo)
+		if err != nil {
+			t.Fatalf("NewInMemoryKey() error = %v", err)
+		}
+
+		armor, err := kr.ExportPrivKeyArmor(info.GetName(), "passphrase")
+		if err != nil {
+			t.Fatalf("ExportPrivKeyArmor() error = %v", err)
+		}
+
+		// The armor carries the algorithm of the key, which takes precedence.
+		_, v, err := NewInMemoryKeyFromPrivKey(armor, "passphrase", "")
+		if err != nil {
+			t.Fatalf("NewInMemoryKeyFromPrivKey() error = %v", err)
+		}
+		if !v.GetAddress().Equals(info.GetAddress()) || v.GetAlgo() != info.GetAlgo() {
+			t.Fatalf("NewInMemoryKeyFromPrivKey() = %s %s, want %s %s", v.GetAddress(), v.GetAlgo(), info.GetAddress(), info.GetAlgo())
+		}
+
+		if _, _, err := NewInMemoryKeyFromPrivKey(armor, "invalid", ""); err == nil {
+			t.Fatalf("NewInMemoryKeyFromPrivKey() with an invalid passphrase error = nil")
+		}
+
+		hexKey, err := keyring.NewUnsafe(kr).UnsafeExportPrivKeyHex(info.GetName())
+		if err != nil {
+			t.Fatalf("UnsafeExportPrivKeyHex() error = %v", err)
+		}
+
+		_, v, err = NewInMemoryKeyFromPrivKey(strings.ToUpper(hexKey), "", algo)
+		if err != nil {
+			t.Fatalf("NewInMemoryKeyFromPrivKey() error = %v", err)
+		}
+		if !v.GetAddress().Equals(info.GetAddress()) {
+			t.Fatalf("NewInMemoryKeyFromPrivKey() address = %s, want %s", v.GetAddress(), info.GetAddress())
+		}
+	}
+}
+
+func TestNewInMemoryKeyFromPrivKey_Invalid(t *testing.T) {
+	for _, v := range []string{"zz", "0x0102", strings.Repeat("00", 33)} {
+		if _, _, err := NewInMemoryKeyFromPrivKey(v, "", ""); err == nil {
+			t.Errorf("NewInMemoryKeyFromPrivKey(%q) error = nil", v)
+		}
+	}
+}
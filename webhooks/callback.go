@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrForbiddenCallback = errors.New("callback URL is not allowed")
+)
+
+// checkCallbackURL accepts the callback URLs given by the callers only over https.
+func checkCallbackURL(s string) error {
+	v, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if v.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %s is not https", ErrForbiddenCallback, v.Scheme)
+	}
+
+	return nil
+}
+
+// isPublicIP tells whether the address is reachable over the internet, rather than a
+// loopback, private, link-local or unspecified address of the network of the server.
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// controlCallbackAddress refuses the connections to the addresses which are not public.
+// It runs once the host of the URL is resolved, so that a host resolving to an internal
+// address is refused as well.
+func controlCallbackAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: address %s is not public", ErrForbiddenCallback, host)
+	}
+
+	return nil
+}
+
+// newCallbackClient returns the client delivering to the callback URLs given by the
+// callers, which only connects to public addresses, directly rather than through a proxy,
+// and does not follow redirects.
+func newCallbackClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: controlCallbackAddress,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,234 @@
+// Package webhooks notifies HTTP endpoints of the events of the transactions and sessions
+// handled by the server.
+//
+// An event is POSTed as JSON to the callback URL given with the request, if any, and to
+// the URLs of the subscriptions to its type. Callback URLs must be https URLs of hosts
+// resolving to public addresses. When a secret is configured, the request
+// carries the Unix time of the delivery in the X-Webhook-Timestamp header and the hex
+// encoded HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret in the
+// X-Webhook-Signature header. A delivery is retried with an exponential backoff on network
+// errors and on the 408, 429 and 5xx status codes, until the endpoint responds with a 2xx
+// status code.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/solarlabsteam/sentinel-api-backend/metrics"
+)
+
+const (
+	DefaultWorkers      = 4
+	DefaultQueueSize    = 1024
+	DefaultMaxRetries   = 5
+	DefaultRetryBackoff = time.Second
+	DefaultTimeout      = 10 * time.Second
+)
+
+const (
+	EventTxConfirmed        = "tx.confirmed"
+	EventTxFailed           = "tx.failed"
+	EventSessionKeyAccepted = "session.key_accepted"
+)
+
+const (
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+var (
+	EventTypes = []string{EventTxConfirmed, EventTxFailed, EventSessionKeyAccepted}
+)
+
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type delivery struct {
+	url      string
+	event    string
+	body     []byte
+	callback bool
+}
+
+// Dispatcher delivers the events in a pool of workers. The URLs of the subscriptions are
+// registered by the administrator, whereas the callback URLs are given by any caller, so
+// these are only delivered to over https and to public addresses.
+type Dispatcher struct {
+	registry       *Registry
+	queue          chan *delivery
+	client         *http.Client
+	callbackClient *http.Client
+
+	secret       []byte
+	maxRetries   int64
+	retryBackoff time.Duration
+}
+
+func NewDispatcher(registry *Registry, secret string, maxRetries int64, retryBackoff, timeout time.Duration) *Dispatcher {
+	return &Dispatcher{
+		registry:       registry,
+		queue:          make(chan *delivery, DefaultQueueSize),
+		client:         &http.Client{Timeout: timeout},
+		callbackClient: newCallbackClient(timeout),
+		secret:         []byte(secret),
+		maxRetries:     maxRetries,
+		retryBackoff:   retryBackoff,
+	}
+}
+
+func NewDefaultDispatcher() *Dispatcher {
+	return NewDispatcher(NewRegistry(), "", DefaultMaxRetries, DefaultRetryBackoff, DefaultTimeout)
+}
+
+func (d *Dispatcher) Registry() *Registry {
+	return d.registry
+}
+
+// Start starts the workers, which stop when the context is done.
+func (d *Dispatcher) Start(ctx context.Context) {
+	for i := 0; i < DefaultWorkers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item := <-d.queue:
+					d.deliver(ctx, item)
+				}
+			}
+		}()
+	}
+}
+
+// Publish queues the delivery of the event to the callback URL, if not empty, and to
+// the subscriptions to the event type. Deliveries are dropped when the queue is full.
+func (d *Dispatcher) Publish(eventType, callbackURL string, data interface{}) {
+	var items []*delivery
+	for _, url := range d.registry.urls(eventType) {
+		items = append(items, &delivery{url: url, event: eventType})
+	}
+	if callbackURL != "" {
+		items = append(items, &delivery{url: callbackURL, event: eventType, callback: true})
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return
+	}
+
+	body, err := json.Marshal(
+		&Event{
+			ID:        id,
+			Type:      eventType,
+			CreatedAt: time.Now().UTC(),
+			Data:      data,
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	for _, item := range items {
+		item.body = body
+
+		select {
+		case d.queue <- item:
+		default:
+			metrics.WebhookDeliveriesTotal.WithLabelValues(eventType, "dropped").Inc()
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, item *delivery) {
+	backoff := d.retryBackoff
+	for attempt := int64(0); ; attempt++ {
+		retry, err := d.post(ctx, item)
+		if err == nil {
+			metrics.WebhookDeliveriesTotal.WithLabelValues(item.event, "success").Inc()
+			return
+		}
+		if !retry || attempt >= d.maxRetries {
+			metrics.WebhookDeliveriesTotal.WithLabelValues(item.event, "failure").Inc()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// post delivers the event, and tells whether a failed delivery is worth retrying, which
+// is the case for network errors and the status codes of timeouts, rate limits and
+// server errors. Other status codes mean the endpoint rejected the event for good.
+func (d *Dispatcher) post(ctx context.Context, item *delivery) (bool, error) {
+	client := d.client
+	if item.callback {
+		if err := checkCallbackURL(item.url); err != nil {
+			return false, err
+		}
+
+		client = d.callbackClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, item.url, bytes.NewReader(item.body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(d.secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, Sign(d.secret, timestamp, item.body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return !errors.Is(err, ErrForbiddenCallback), err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusRequestTimeout ||
+			resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook %s responded with status code %d", item.url, resp.StatusCode)
+	}
+
+	return false, nil
+}
+
+// Sign returns the signature of the body delivered at the given timestamp.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
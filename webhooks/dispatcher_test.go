@@ -0,0 +1,199 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Publish(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The endpoints are told apart by their paths.
+	paths := make(chan string, 8)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+		if event.Type != EventTxConfirmed {
+			t.Errorf("event type = %s, want %s", event.Type, EventTxConfirmed)
+		}
+
+		timestamp := r.Header.Get(HeaderTimestamp)
+		if want := Sign([]byte("secret"), timestamp, body); r.Header.Get(HeaderSignature) != want {
+			t.Errorf("signature = %s, want %s", r.Header.Get(HeaderSignature), want)
+		}
+
+		paths <- r.URL.Path
+	}))
+	defer server.Close()
+
+	// The callback URL is on a loopback address, which only the client of the test server
+	// connects to.
+	d := NewDispatcher(NewRegistry(), "secret", 0, time.Millisecond, time.Second)
+	d.client = server.Client()
+	d.callbackClient = server.Client()
+	d.Start(ctx)
+
+	if _, err := d.Registry().Add(server.URL+"/subscriber", []string{EventTxConfirmed}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := d.Registry().Add(server.URL+"/other", []string{EventTxFailed}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	d.Publish(EventTxConfirmed, server.URL+"/callback", map[string]string{"tx_hash": "ABCDEF"})
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case path := <-paths:
+			got = append(got, path)
+		case <-time.After(time.Second):
+			t.Fatalf("delivered to %v, want /callback and /subscriber", got)
+		}
+	}
+
+	sort.Strings(got)
+	if got[0] != "/callback" || got[1] != "/subscriber" {
+		t.Fatalf("delivered to %v, want /callback and /subscriber", got)
+	}
+
+	select {
+	case path := <-paths:
+		t.Fatalf("delivered to %s, which is subscribed to another event", path)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts int32
+	done := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		done <- r
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(NewRegistry(), "", 2, time.Millisecond, time.Second)
+	d.Start(ctx)
+
+	if _, err := d.Registry().Add(server.URL, []string{EventTxFailed}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	d.Publish(EventTxFailed, "", nil)
+
+	select {
+	case r := <-done:
+		if r.Header.Get(HeaderSignature) != "" || r.Header.Get(HeaderTimestamp) != "" {
+			t.Fatalf("unsigned delivery carries %s and %s", HeaderSignature, HeaderTimestamp)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("webhook was not delivered after %d attempts", atomic.LoadInt32(&attempts))
+	}
+}
+
+func TestDispatcher_Rejected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(NewRegistry(), "", 2, time.Millisecond, time.Second)
+	d.Start(ctx)
+
+	// The endpoint rejects the event for good, so the delivery is not retried.
+	if _, err := d.Registry().Add(server.URL, []string{EventTxFailed}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	d.Publish(EventTxFailed, "", nil)
+	time.Sleep(50 * time.Millisecond)
+
+	if v := atomic.LoadInt32(&attempts); v != 1 {
+		t.Fatalf("attempts = %d, want 1", v)
+	}
+}
+
+func TestDispatcher_Callback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+
+	d := NewDispatcher(NewRegistry(), "", 2, time.Millisecond, time.Second)
+	d.Start(ctx)
+
+	// Callbacks are refused over http and to the addresses of the network of the server.
+	d.Publish(EventTxConfirmed, server.URL, nil)
+	d.Publish(EventTxConfirmed, tlsServer.URL, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	if v := atomic.LoadInt32(&attempts); v != 0 {
+		t.Fatalf("attempts = %d, want 0", v)
+	}
+
+	if _, err := d.post(ctx, &delivery{url: server.URL, callback: true}); !errors.Is(err, ErrForbiddenCallback) {
+		t.Fatalf("post() error = %v, want %v", err, ErrForbiddenCallback)
+	}
+	if retry, err := d.post(ctx, &delivery{url: tlsServer.URL, callback: true}); retry || !errors.Is(err, ErrForbiddenCallback) {
+		t.Fatalf("post() = %t, %v, want false, %v", retry, err, ErrForbiddenCallback)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"1.1.1.1":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.0.0.1":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+	}
+
+	for s, want := range tests {
+		if got := isPublicIP(net.ParseIP(s)); got != want {
+			t.Errorf("isPublicIP(%s) = %t, want %t", s, got, want)
+		}
+	}
+}
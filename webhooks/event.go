@@ -0,0 +1,21 @@
+package webhooks
+
+import (
+	"github.com/solarlabsteam/sentinel-api-backend/types"
+)
+
+// TxFailed is the data of the tx.failed events. The hash is empty when the transaction
+// failed before it was broadcast.
+type TxFailed struct {
+	Hash  string       `json:"hash,omitempty"`
+	Error *types.Error `json:"error"`
+}
+
+// SessionKeyAccepted is the data of the session.key_accepted events.
+type SessionKeyAccepted struct {
+	TxHash      string  `json:"tx_hash"`
+	SessionID   uint64  `json:"session_id"`
+	AccAddress  string  `json:"acc_address"`
+	NodeAddress string  `json:"node_address"`
+	NodeType    float64 `json:"node_type"`
+}
@@ -0,0 +1,101 @@
+package webhooks
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+)
+
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s *Subscription) matches(eventType string) bool {
+	for _, v := range s.Events {
+		if v == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Registry keeps the webhook subscriptions in memory.
+type Registry struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*Subscription
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		subscriptions: make(map[string]*Subscription),
+	}
+}
+
+func (r *Registry) Add(url string, events []string) (*Subscription, error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Subscription{
+		ID:        id,
+		URL:       url,
+		Events:    events,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[id] = v
+	return v, nil
+}
+
+// List returns the subscriptions in the order they were created.
+func (r *Registry) List() []*Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]*Subscription, 0, len(r.subscriptions))
+	for _, v := range r.subscriptions {
+		items = append(items, v)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	return items
+}
+
+func (r *Registry) Delete(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return false
+	}
+
+	delete(r.subscriptions, id)
+	return true
+}
+
+func (r *Registry) urls(eventType string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var urls []string
+	for _, v := range r.subscriptions {
+		if v.matches(eventType) {
+			urls = append(urls, v.URL)
+		}
+	}
+
+	return urls
+}